@@ -152,6 +152,10 @@ func main() {
 		tagger.Expand(rules)
 		log.Printf("expanded %d meta-ISIL(s)", len(rules))
 	}
+	evaluator, err := tagger.Compile()
+	if err != nil {
+		log.Fatalf("compile filterconfig: %v", err)
+	}
 
 	// Open input (zstd compressed).
 	inf, err := os.Open(inputFile)
@@ -193,7 +197,7 @@ func main() {
 			return nil, fmt.Errorf("to intermediate schema: %w", err)
 		}
 		// Stage 2: tag (apply filter rules).
-		tagged := tagger.Tag(*is)
+		tagged := evaluator.Tag(*is)
 		// Stage 3: export (intermediate schema -> solr).
 		var exporter finc.Solr5Vufind3
 		bb, err := exporter.Export(tagged, true)
@@ -0,0 +1,107 @@
+// span-filter-diff compares two filter configurations, given as plain JSON
+// files or frozen zip files (from span-freeze), and prints changes per ISIL:
+// added or removed ISILs, added or removed filter values and, for holdings
+// files, titles added or removed and coverage or embargo changes, like
+// span-kbart-diff reports them. Holdings are compared by content, so renamed
+// files in frozen zips do not show up.
+//
+// Usage:
+//
+//	$ span-filter-diff old.zip new.zip
+//	~ DE-14
+//	  + collection: Springer Journals
+//	  - source: 55
+//	  ~ holdings: +12 titles, -3 titles, 5 coverage changes, 1 embargo changes
+//	+ DE-15
+//	  + source: 49
+//
+//	$ span-filter-diff -l old.json new.json   # list changes per title
+//	$ span-filter-diff -json old.zip new.zip  # one JSON document per ISIL
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span"
+	"github.com/miku/span/filter"
+	"github.com/miku/span/licensing/kbart"
+)
+
+var (
+	asJSON      = flag.Bool("json", false, "emit one JSON document per changed ISIL")
+	listTitles  = flag.Bool("l", false, "list each title change, not just counts")
+	showVersion = flag.Bool("v", false, "show version")
+)
+
+// writeText writes a readable diff for a single label.
+func writeText(w io.Writer, d filter.LabelDiff) {
+	var marker = map[filter.Status]string{
+		filter.StatusAdded:   "+",
+		filter.StatusRemoved: "-",
+		filter.StatusChanged: "~",
+	}
+	fmt.Fprintf(w, "%s %s\n", marker[d.Status], d.Label)
+	for _, v := range d.Added {
+		fmt.Fprintf(w, "  + %s\n", v)
+	}
+	for _, v := range d.Removed {
+		fmt.Fprintf(w, "  - %s\n", v)
+	}
+	if d.Structure {
+		fmt.Fprintf(w, "  ~ structure changed\n")
+	}
+	if len(d.Holdings) == 0 {
+		return
+	}
+	summary := make(map[kbart.ChangeKind]int)
+	for _, c := range d.Holdings {
+		summary[c.Kind]++
+	}
+	fmt.Fprintf(w, "  ~ holdings: +%d titles, -%d titles, %d coverage changes, %d embargo changes\n",
+		summary[kbart.Added], summary[kbart.Removed], summary[kbart.CoverageChanged], summary[kbart.EmbargoChanged])
+	if !*listTitles {
+		return
+	}
+	for _, c := range d.Holdings {
+		fmt.Fprintf(w, "    %s\t%s\t%s\t%s\t%s\t%s\n", c.Kind, c.Key, c.Title, strings.Join(c.ISSN, ";"), c.Old, c.New)
+	}
+}
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if flag.NArg() != 2 {
+		log.Fatal("usage: span-filter-diff [-json] [-l] OLD NEW")
+	}
+	a, err := filter.ReadTagger(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := filter.ReadTagger(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	bw := bufio.NewWriter(os.Stdout)
+	defer bw.Flush()
+	enc := json.NewEncoder(bw)
+	for _, d := range filter.Diff(a, b) {
+		if *asJSON {
+			if err := enc.Encode(d); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		writeText(bw, d)
+	}
+}
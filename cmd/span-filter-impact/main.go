@@ -0,0 +1,147 @@
+// span-filter-impact estimates the effect of a filterconfig change. It tags
+// intermediate schema records with an old and a new configuration (plain JSON
+// or frozen zip) in a single pass and reports, per ISIL, how many records are
+// attached before and after, and how many would be gained or lost, together
+// with a few example record ids. Running it on a sample is much cheaper than
+// two full span-tag runs plus span-compare-file.
+//
+// Usage:
+//
+//	$ span-filter-impact -old old.zip -new new.zip sample.ldj
+//	ISIL   OLD    NEW    GAINED  LOST  CHANGE  EXAMPLES
+//	DE-14  10231  10655  512     88    4.14    +ai-49-..., -ai-49-...
+//	...
+//
+//	$ zstdcat file.zst | span-filter-impact -old old.json -new new.json -json
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span"
+	"github.com/miku/span/filter"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/parallel"
+)
+
+var (
+	oldConfig   = flag.String("old", "", "old filterconfig, JSON or frozen zip (required)")
+	newConfig   = flag.String("new", "", "new filterconfig, JSON or frozen zip (required)")
+	examples    = flag.Int("x", 3, "number of example ids per ISIL for gained and lost records")
+	showAll     = flag.Bool("a", false, "show unchanged ISILs, too")
+	asJSON      = flag.Bool("json", false, "emit one JSON document per ISIL")
+	numWorkers  = flag.Int("w", runtime.NumCPU(), "number of workers")
+	batchSize   = flag.Int("b", 20000, "batch size")
+	showVersion = flag.Bool("v", false, "show version")
+)
+
+// mustCompile loads and compiles a filterconfig.
+func mustCompile(filename string) *filter.Evaluator {
+	tagger, err := filter.ReadTagger(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	e, err := tagger.Compile()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return e
+}
+
+// change returns the relative change in percent.
+func change(li filter.LabelImpact) float64 {
+	if li.Old == 0 {
+		return 100
+	}
+	return 100 * float64(li.New-li.Old) / float64(li.Old)
+}
+
+// exampleList formats gained and lost examples.
+func exampleList(li filter.LabelImpact) string {
+	var s []string
+	for _, id := range li.GainedExamples {
+		s = append(s, "+"+id)
+	}
+	for _, id := range li.LostExamples {
+		s = append(s, "-"+id)
+	}
+	return strings.Join(s, ", ")
+}
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if *oldConfig == "" || *newConfig == "" {
+		log.Fatal("both -old and -new filterconfig required")
+	}
+	var (
+		before = mustCompile(*oldConfig)
+		after  = mustCompile(*newConfig)
+		impact = filter.NewImpact(*examples)
+		r      io.Reader
+	)
+	switch {
+	case flag.NArg() > 0:
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	default:
+		r = os.Stdin
+	}
+	p := parallel.NewProcessor(bufio.NewReader(r), io.Discard, func(_ int64, b []byte) ([]byte, error) {
+		var is finc.IntermediateSchema
+		if err := json.Unmarshal(b, &is); err != nil {
+			return nil, err
+		}
+		is.Labels = nil
+		impact.Observe(is.ID, before.Tag(is).Labels, after.Tag(is).Labels)
+		return nil, nil
+	})
+	p.BatchSize = *batchSize
+	p.NumWorkers = *numWorkers
+	if err := p.Run(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("[span-filter-impact] %d records", impact.Records())
+	bw := bufio.NewWriter(os.Stdout)
+	defer bw.Flush()
+	var (
+		enc = json.NewEncoder(bw)
+		tw  = tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+	)
+	if !*asJSON {
+		fmt.Fprintln(tw, "ISIL\tOLD\tNEW\tGAINED\tLOST\tCHANGE\tEXAMPLES")
+	}
+	for _, li := range impact.Result() {
+		if li.Gained == 0 && li.Lost == 0 && !*showAll {
+			continue
+		}
+		if *asJSON {
+			if err := enc.Encode(li); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%0.2f\t%s\n",
+			li.Label, li.Old, li.New, li.Gained, li.Lost, change(li), exampleList(li))
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
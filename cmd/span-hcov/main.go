@@ -1,4 +1,18 @@
-// The span-hcov tool will generate a simple coverage report given a holding file in KBART format.
+// The span-hcov tool will generate a coverage report given a holding file in
+// KBART format. For each entry and publication year, the number of documents
+// inside and outside of the licensed coverage is reported, as well as covered
+// years without any document. Documents are counted in a SOLR index or in an
+// intermediate schema file.
+//
+// Usage:
+//
+//	$ span-hcov -f kbart.txt -server 10.1.1.1:8085/solr/biblio
+//	title                issn                 first_date  last_date  embargo  year  status   count
+//	Journal of Examples  0028-0844 1050-124X  2000        2003                1999  outside  1
+//	Journal of Examples  0028-0844 1050-124X  2000        2003                2000  missing  0
+//	...
+//
+//	$ span-hcov -f kbart.xlsx -is file.is -format xlsx -o coverage.xlsx
 package main
 
 import (
@@ -11,26 +25,60 @@ import (
 	"strings"
 	"time"
 
-	"github.com/segmentio/encoding/json"
-
-	"github.com/miku/span/container"
+	"github.com/miku/span"
+	"github.com/miku/span/licensing"
+	"github.com/miku/span/licensing/coverage"
 	"github.com/miku/span/licensing/kbart"
 	"github.com/miku/span/solrutil"
 )
 
 var (
-	holdingsFile = flag.String("f", "", "path to holdings file in KBART format (not all CSV files will work)")
+	holdingsFile = flag.String("f", "", "path to holdings file in KBART format (TSV, CSV, XLSX or ODS)")
 	issnList     = flag.String("l", "", "path to ISSN list (1234-789X), one per line, empty lines ignored (overrides -f)")
-	server       = flag.String("server", "", "server url to check agains")
+	server       = flag.String("server", "", "server url to check against")
+	isFile       = flag.String("is", "", "intermediate schema file to check against, instead of a server")
+	format       = flag.String("format", "tsv", "output format: tsv, json, xlsx")
+	output       = flag.String("o", "", "output file, default: stdout")
+	showVersion  = flag.Bool("v", false, "show version")
 )
 
+// readISSNList returns one entry per ISSN in a list.
+func readISSNList(r io.Reader) (entries []licensing.Entry, err error) {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		entries = append(entries, licensing.Entry{PrintIdentifier: line})
+	}
+	return entries, nil
+}
+
 func main() {
 	flag.Parse()
-	*server = solrutil.PrependHTTP(*server)
-
-	// List of serial numbers.
-	var hlist, ilist []string
-
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	var write func(io.Writer, []coverage.Report) error
+	switch *format {
+	case "tsv":
+		write = coverage.WriteTSV
+	case "json":
+		write = coverage.WriteJSON
+	case "xlsx":
+		write = coverage.WriteXLSX
+	default:
+		log.Fatalf("unknown format: %s", *format)
+	}
+	var entries []licensing.Entry
 	switch {
 	case *issnList != "":
 		f, err := os.Open(*issnList)
@@ -38,95 +86,55 @@ func main() {
 			log.Fatal(err)
 		}
 		defer f.Close()
-		br := bufio.NewReader(f)
-		unique := container.NewStringSet()
-		for {
-			line, err := br.ReadString('\n')
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Fatal(err)
-			}
-			line = strings.TrimSpace(line)
-			if line == "" {
-				continue
-			}
-			unique.Add(line)
+		if entries, err = readISSNList(f); err != nil {
+			log.Fatal(err)
 		}
-		ilist = normalizeSerialNumbers(unique.SortedValues())
 	case *holdingsFile != "":
 		f, err := os.Open(*holdingsFile)
 		if err != nil {
 			log.Fatal(err)
 		}
 		defer f.Close()
-		hlist, err = holdingsSerialNumbers(f)
-		if err != nil {
+		var holdings kbart.Holdings
+		if _, err := holdings.ReadFrom(f); err != nil {
 			log.Fatal(err)
 		}
+		entries = holdings
 	default:
 		log.Fatal("holdings file or issn list required")
 	}
-
-	ilist, err := indexSerialNumbers(*server)
-	if err != nil {
-		log.Fatal(err)
+	var counter coverage.Counter
+	switch {
+	case *isFile != "":
+		f, err := os.Open(*isFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if counter, err = coverage.ReadFile(f); err != nil {
+			log.Fatal(err)
+		}
+	case *server != "":
+		counter = &coverage.IndexCounter{
+			Index: solrutil.Index{Server: solrutil.PrependHTTP(*server)},
+		}
+	default:
+		log.Fatal("server or intermediate schema file required")
 	}
-
-	// Use sets.
-	hset := container.NewStringSet(hlist...)
-	iset := container.NewStringSet(ilist...)
-
-	coveragePct := float64(hset.Intersection(iset).Size()) / float64(hset.Size())
-
-	b, err := json.Marshal(map[string]any{
-		"coverage_pct":        fmt.Sprintf("%0.2f%%", coveragePct*100),
-		"date":                time.Now(),
-		"holdings":            hset.Size(),
-		"holdings_file":       *holdingsFile,
-		"holdings_only":       hset.Difference(iset).SortedValues(),
-		"holdings_only_count": hset.Difference(iset).Size(),
-		"index":               iset.Size(),
-		"index_url":           *server,
-		"intersection":        hset.Intersection(iset).Size(),
-	})
+	reports, err := coverage.Run(entries, counter, time.Now())
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(string(b))
-}
-
-// normalizeSerialNumbers converts identifiers to some canonical notation.
-func normalizeSerialNumbers(s []string) (result []string) {
-	for _, e := range s {
-		r := strings.ToUpper(e)
-		if len(r) == 8 {
-			r = fmt.Sprintf("%s-%s", r[:4], r[4:])
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
 		}
-		result = append(result, r)
-	}
-	return normalizeSerialNumbers(result)
-}
-
-// indexSerialNumbers returns a unique list of ISSN from a SOLR index.
-func indexSerialNumbers(server string) ([]string, error) {
-	index := solrutil.Index{Server: server, FacetLimit: 1000000}
-	return index.FacetKeys("*:*", "issn")
-}
-
-// holdingsSerialNumbers returns a list of unique serial numbers found in the
-// holding data (kbart).
-func holdingsSerialNumbers(r io.Reader) ([]string, error) {
-	holdings := new(kbart.Holdings)
-	if _, err := holdings.ReadFrom(r); err != nil {
-		return nil, err
+		defer f.Close()
+		w = f
 	}
-	unique := container.NewStringSet()
-	for _, entry := range *holdings {
-		for _, issn := range entry.ISSNList() {
-			unique.Add(issn)
-		}
+	if err := write(w, reports); err != nil {
+		log.Fatal(err)
 	}
-	return normalizeSerialNumbers(unique.SortedValues()), nil
 }
@@ -17,8 +17,10 @@ import (
 	"log/slog"
 
 	"github.com/miku/span"
+	"github.com/miku/span/formats/bibtex"
 	"github.com/miku/span/formats/ceeol"
 	"github.com/miku/span/formats/crossref"
+	"github.com/miku/span/formats/datacite"
 	"github.com/miku/span/formats/dblp"
 	"github.com/miku/span/formats/degruyter"
 	"github.com/miku/span/formats/doaj"
@@ -33,8 +35,12 @@ import (
 	"github.com/miku/span/formats/imslp"
 	"github.com/miku/span/formats/ios"
 	"github.com/miku/span/formats/jstor"
+	"github.com/miku/span/formats/marc"
 	"github.com/miku/span/formats/mediarep"
 	"github.com/miku/span/formats/olms"
+	"github.com/miku/span/formats/openalex"
+	"github.com/miku/span/formats/pubmed"
+	"github.com/miku/span/formats/ris"
 	"github.com/miku/span/formats/ssoar"
 	"github.com/miku/span/formats/thieme"
 	"github.com/miku/span/formats/zvdd"
@@ -54,6 +60,9 @@ var (
 	memProfile  = flag.String("memprofile", "", "write heap profile to file (go tool pprof -png --alloc_objects program mem.pprof > mem.png)")
 	logfile     = flag.String("logfile", "", "path to logfile to append to, otherwise stderr")
 	verbose     = flag.Bool("verbose", false, "be verbose")
+	mappingFile = flag.String("m", "", "path to JSON field mapping for marc21 and marcxml, must set source_id")
+	sourceID    = flag.String("sid", "", "source id for datacite, openalex, pubmed, marc21, marcxml, ris and bibtex, overrides mapping")
+	collection  = flag.String("mc", "", "mega collection for marc21, marcxml, ris and bibtex, overrides mapping")
 )
 
 // Factory creates things.
@@ -62,9 +71,12 @@ type Factory func() any
 // FormatMap maps format name to pointer to format struct. TODO(miku): That
 // looks just wrong.
 var FormatMap = map[string]Factory{
+	"bibtex":        func() any { return new(bibtex.Entry) },
 	"ceeol":         func() any { return new(ceeol.Article) },
 	"ceeol-marcxml": func() any { return new(ceeol.Record) },
 	"crossref":      func() any { return new(crossref.Document) },
+	"datacite":      func() any { return new(datacite.Document) },
+	"datacite-xml":  func() any { return new(datacite.Resource) },
 	"dblp":          func() any { return new(dblp.Article) },
 	"degruyter":     func() any { return new(degruyter.Article) },
 	"doaj":          func() any { return new(doaj.ArticleV1) },
@@ -79,9 +91,14 @@ var FormatMap = map[string]Factory{
 	"imslp":         func() any { return new(imslp.Data) },
 	"ios":           func() any { return new(ios.Article) },
 	"jstor":         func() any { return new(jstor.Article) },
+	"marc21":        func() any { return new(marc.Marc21Record) },
+	"marcxml":       func() any { return new(marc.Marc21Record) },
 	"mediarep-dim":  func() any { return new(mediarep.Dim) },
 	"olms":          func() any { return new(olms.Record) },
 	"olms-mets":     func() any { return new(olms.MetsRecord) },
+	"openalex":      func() any { return new(openalex.Work) },
+	"pubmed":        func() any { return new(pubmed.Article) },
+	"ris":           func() any { return new(ris.Record) },
 	"ssoar":         func() any { return new(ssoar.Record) },
 	"thieme-nlm":    func() any { return new(thieme.Record) },
 	"zvdd":          func() any { return new(zvdd.DublicCoreRecord) },
@@ -93,6 +110,21 @@ type IntermediateSchemaer interface {
 	ToIntermediateSchema() (*finc.IntermediateSchema, error)
 }
 
+// setSourceID sets the source id of a record, for formats without a fixed
+// source id, refs. -sid.
+func setSourceID(v any, sid string) {
+	switch r := v.(type) {
+	case *datacite.Document:
+		r.SourceID = sid
+	case *datacite.Resource:
+		r.SourceID = sid
+	case *openalex.Work:
+		r.SourceID = sid
+	case *pubmed.Article:
+		r.SourceID = sid
+	}
+}
+
 // processXML converts XML based formats, given a format name. It reads XML as
 // stream and converts record them to an intermediate schema (at the moment).
 func processXML(r io.Reader, w io.Writer, name string) error {
@@ -106,6 +138,7 @@ func processXML(r io.Reader, w io.Writer, name string) error {
 	scanner.Decoder.CharsetReader = charset.NewReaderLabel
 	for scanner.Scan() {
 		tag := scanner.Element()
+		setSourceID(tag, *sourceID)
 		converter, ok := tag.(IntermediateSchemaer)
 		if !ok {
 			return fmt.Errorf("cannot convert to intermediate schema: %T", tag)
@@ -127,6 +160,96 @@ func processXML(r io.Reader, w io.Writer, name string) error {
 	return scanner.Err()
 }
 
+// processRecords converts records from a reader function until io.EOF.
+func processRecords(w io.Writer, next func() (IntermediateSchemaer, error)) error {
+	enc := json.NewEncoder(w)
+	for {
+		record, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		output, err := record.ToIntermediateSchema()
+		if err != nil {
+			if _, ok := err.(span.Skip); ok {
+				if *verbose {
+					log.Printf("%v", err)
+				}
+				continue
+			}
+			return err
+		}
+		if err := enc.Encode(output); err != nil {
+			return err
+		}
+	}
+}
+
+// processMARC converts generic MARC21 records, either binary (marc21) or
+// MARCXML (marcxml), with a field mapping.
+func processMARC(r io.Reader, w io.Writer, name string, mapping *marc.Mapping) error {
+	var next func() (*marc.Marc21Record, error)
+	switch name {
+	case "marc21":
+		mr := marc.NewReader(r)
+		next = mr.Read
+	case "marcxml":
+		scanner := xmlstream.NewScanner(bufio.NewReader(r), new(marc.Marc21Record))
+		scanner.Decoder.Strict = false
+		scanner.Decoder.CharsetReader = charset.NewReaderLabel
+		next = func() (*marc.Marc21Record, error) {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			return scanner.Element().(*marc.Marc21Record), nil
+		}
+	default:
+		return fmt.Errorf("unknown format name: %s", name)
+	}
+	return processRecords(w, func() (IntermediateSchemaer, error) {
+		record, err := next()
+		if err != nil {
+			return nil, err
+		}
+		record.Mapping = mapping
+		return record, nil
+	})
+}
+
+// processCitations converts RIS or BibTeX exports. Both formats lack a
+// source id and collection, they are taken from the command line.
+func processCitations(r io.Reader, w io.Writer, name, sid string, collections []string) error {
+	switch name {
+	case "ris":
+		rr := ris.NewReader(r)
+		return processRecords(w, func() (IntermediateSchemaer, error) {
+			record, err := rr.Read()
+			if err != nil {
+				return nil, err
+			}
+			record.SourceID, record.MegaCollections = sid, collections
+			return record, nil
+		})
+	case "bibtex":
+		br := bibtex.NewReader(r)
+		return processRecords(w, func() (IntermediateSchemaer, error) {
+			entry, err := br.Read()
+			if err != nil {
+				return nil, err
+			}
+			entry.SourceID, entry.MegaCollections = sid, collections
+			return entry, nil
+		})
+	default:
+		return fmt.Errorf("unknown format name: %s", name)
+	}
+}
+
 // processJSON convert JSON based formats. Input is interpreted as newline delimited JSON.
 func processJSON(r io.Reader, w io.Writer, name string) error {
 	if _, ok := FormatMap[name]; !ok {
@@ -137,6 +260,7 @@ func processJSON(r io.Reader, w io.Writer, name string) error {
 		if err := json.Unmarshal(b, v); err != nil {
 			return nil, err
 		}
+		setSourceID(v, *sourceID)
 		converter, ok := v.(IntermediateSchemaer)
 		if !ok {
 			return nil, fmt.Errorf("cannot convert to intermediate schema: %T", v)
@@ -244,11 +368,19 @@ func main() {
 		}
 		reader = io.MultiReader(files...)
 	}
+	// Formats without a fixed source id would fail for every record.
+	switch *name {
+	case "bibtex", "datacite", "datacite-xml", "openalex", "pubmed", "ris":
+		if *sourceID == "" {
+			log.Fatalf("format %s requires a source id, use -sid", *name)
+		}
+	}
 	switch *name {
 	// XXX: Configure this in one place.
 	case
 		"ceeol",
 		"ceeol-marcxml",
+		"datacite-xml",
 		"dblp",
 		"degruyter",
 		"doaj-oai",
@@ -262,6 +394,7 @@ func main() {
 		"mediarep-dim",
 		"olms",
 		"olms-mets",
+		"pubmed",
 		"ssoar",
 		"thieme-nlm",
 		"thieme-tm",
@@ -272,12 +405,47 @@ func main() {
 		}
 	case
 		"crossref",
+		"datacite",
 		"doaj",
 		"doaj-api",
-		"dummy":
+		"dummy",
+		"openalex":
 		if err := processJSON(reader, w, *name); err != nil {
 			log.Fatal(err)
 		}
+	case "marc21", "marcxml":
+		defaults := marc.DefaultMapping
+		mapping := &defaults
+		if *mappingFile != "" {
+			f, err := os.Open(*mappingFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			if mapping, err = marc.ReadMapping(f); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *sourceID != "" {
+			mapping.SourceID = *sourceID
+		}
+		if *collection != "" {
+			mapping.MegaCollections = []string{*collection}
+		}
+		if mapping.SourceID == "" {
+			log.Fatalf("format %s requires a source id, use -sid or set source_id in mapping", *name)
+		}
+		if err := processMARC(reader, w, *name, mapping); err != nil {
+			log.Fatal(err)
+		}
+	case "ris", "bibtex":
+		var collections []string
+		if *collection != "" {
+			collections = []string{*collection}
+		}
+		if err := processCitations(reader, w, *name, *sourceID, collections); err != nil {
+			log.Fatal(err)
+		}
 	case "imslp":
 		if err := processText(reader, w, *name); err != nil {
 			log.Fatal(err)
@@ -0,0 +1,127 @@
+// span-kbart-diff compares two versions of a KBART holdings file and reports
+// added and removed titles as well as coverage and embargo changes. Titles are
+// matched by title_id, then by ISSN. With -server, the number of documents in
+// the index with an affected ISSN is added, to estimate the impact of an
+// update, like span-hcov does for a single file.
+//
+// Usage:
+//
+//	$ span-kbart-diff kbart-2024-01.txt kbart-2024-02.txt
+//	added     12345  Journal of Examples  1234-5678  //-//  ...
+//	coverage  22540  Another Journal      2029-8692  1901/1/-1997/25/  1901/1/-2005/33/
+//
+//	$ span-kbart-diff -json -server 10.1.1.7:8085/solr/biblio old.txt new.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span"
+	"github.com/miku/span/licensing/kbart"
+	"github.com/miku/span/solrutil"
+)
+
+var (
+	server      = flag.String("server", "", "if set, count documents per affected ISSN in this index")
+	asJSON      = flag.Bool("json", false, "emit one JSON document per change")
+	showVersion = flag.Bool("v", false, "show version")
+)
+
+// Row is a change with an optional estimate of affected documents.
+type Row struct {
+	kbart.Change
+	Documents *int `json:"documents,omitempty"`
+}
+
+// readHoldings reads a KBART file.
+func readHoldings(filename string) (kbart.Holdings, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var h kbart.Holdings
+	if _, err := h.ReadFrom(bufio.NewReader(f)); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return h, nil
+}
+
+// serialNumberCounts returns the number of documents per ISSN in an index.
+func serialNumberCounts(server string) (solrutil.FacetMap, error) {
+	index := solrutil.Index{Server: solrutil.PrependHTTP(server), FacetLimit: 1000000}
+	resp, err := index.FacetQuery("*:*", "issn")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Facets()
+}
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	if flag.NArg() != 2 {
+		log.Fatal("usage: span-kbart-diff [-json] [-server URL] OLD NEW")
+	}
+	before, err := readHoldings(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	after, err := readHoldings(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var counts solrutil.FacetMap
+	if *server != "" {
+		if counts, err = serialNumberCounts(*server); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var (
+		bw      = bufio.NewWriter(os.Stdout)
+		enc     = json.NewEncoder(bw)
+		changes = kbart.Diff(before, after)
+		summary = make(map[kbart.ChangeKind]int)
+		total   int
+	)
+	defer bw.Flush()
+	for _, c := range changes {
+		row := Row{Change: c}
+		if counts != nil {
+			// Documents with multiple ISSN may be counted more than once.
+			var n int
+			for _, issn := range c.ISSN {
+				n += counts[issn]
+			}
+			row.Documents = &n
+			total += n
+		}
+		summary[c.Kind]++
+		if *asJSON {
+			if err := enc.Encode(row); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		fields := []string{string(c.Kind), c.Key, c.Title, strings.Join(c.ISSN, ";"), c.Old, c.New}
+		if row.Documents != nil {
+			fields = append(fields, fmt.Sprintf("%d", *row.Documents))
+		}
+		fmt.Fprintln(bw, strings.Join(fields, "\t"))
+	}
+	log.Printf("[span-kbart-diff] %d added, %d removed, %d coverage, %d embargo changes",
+		summary[kbart.Added], summary[kbart.Removed], summary[kbart.CoverageChanged], summary[kbart.EmbargoChanged])
+	if counts != nil {
+		log.Printf("[span-kbart-diff] about %d documents affected", total)
+	}
+}
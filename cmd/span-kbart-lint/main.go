@@ -0,0 +1,101 @@
+// span-kbart-lint checks a KBART holdings file for problems, that would
+// otherwise be silently accepted when the file is loaded: malformed ISSN,
+// unparsable dates, invalid embargo strings, inverted ranges and missing KBART
+// Phase II columns. Problems are reported with line number and severity. The
+// program exits with a non-zero status, if there are errors, so it can be
+// used before a holdings file is referenced in a filterconfig. TSV, CSV, XLSX
+// and ODS files are read the same way as by the holdings filter.
+//
+// Usage:
+//
+//	$ span-kbart-lint kbart.txt
+//	1    warning  publication_type         missing KBART Phase II column
+//	17   error    print_identifier         invalid ISSN check digit: 0028-0841
+//	233  error    embargo_info             invalid embargo: 1Y
+//
+//	$ span-kbart-lint -o normalized.tsv -drop < kbart.txt
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span"
+	"github.com/miku/span/licensing/kbart"
+)
+
+var (
+	output       = flag.String("o", "", "write normalized KBART file to this path")
+	dropInvalid  = flag.Bool("drop", false, "omit rows with errors from normalized output")
+	errorsOnly   = flag.Bool("e", false, "report errors only")
+	asJSON       = flag.Bool("json", false, "emit one JSON document per problem")
+	noExitStatus = flag.Bool("x", false, "exit with zero status, even if there are errors")
+	showVersion  = flag.Bool("v", false, "show version")
+)
+
+func main() {
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(span.AppVersion)
+		os.Exit(0)
+	}
+	var r io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+	linter := kbart.Linter{DropInvalid: *dropInvalid}
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		linter.Output = f
+	}
+	problems, err := linter.Run(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var (
+		bw     = bufio.NewWriter(os.Stdout)
+		tw     = tabwriter.NewWriter(bw, 0, 0, 2, ' ', 0)
+		enc    = json.NewEncoder(bw)
+		errors int
+	)
+	for _, p := range problems {
+		if p.Severity == kbart.SeverityError {
+			errors++
+		} else if *errorsOnly {
+			continue
+		}
+		if *asJSON {
+			if err := enc.Encode(p); err != nil {
+				log.Fatal(err)
+			}
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", p.Line, p.Severity, p.Column, p.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("[span-kbart-lint] %d problems, %d errors", len(problems), errors)
+	if errors > 0 && !*noExitStatus {
+		os.Exit(1)
+	}
+}
@@ -1,11 +1,21 @@
-// span-oa-filter will set x.oa to true, if the given KBART file validates a record.
+// span-oa-filter sets x.oa for records, based on several sources of evidence:
+// source ids, AMSL free content information, a KBART file, the DOAJ journal
+// list, license URLs and a list of open access DOI, e.g. from an Unpaywall
+// snapshot. The evidence that decided is recorded in x.oa_evidence.
+//
+// Usage:
+//
+//	$ span-oa-filter -f kbart.txt -doaj doaj.csv -license -dois unpaywall.jsonl.gz < in.is > out.is
 package main
 
 import (
 	"bufio"
+	"compress/gzip"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/segmentio/encoding/json"
@@ -15,51 +25,29 @@ import (
 	"github.com/miku/span"
 	"github.com/miku/span/filter"
 	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing/openaccess"
 	"github.com/miku/span/parallel"
 	"github.com/miku/span/xflag"
 )
 
-// FreeContentItem is a single item from the API response (2017-12-01).
-type FreeContentItem struct {
-	FreeContent    string `json:"freeContent"`
-	MegaCollection string `json:"mega_collection"`
-	Shard          string `json:"shard"`
-	Sid            string `json:"sid"`
-}
-
-// FreeContentLookup maps a string of the form "Sid:MegaCollection" to a bool,
-// indicating free access (true) and uncertainty or closed access (false).
-type FreeContentLookup map[string]bool
-
-// createFreeContentLookup creates a map for fast lookups in loops. Filename
-// contains AMSL API response (2017-12-01).
-// XXX: This can take up significant memory (e.g. 40% of 16G).
-func createFreeContentLookup(filename string) (FreeContentLookup, error) {
-	lookup := make(FreeContentLookup)
-
+// openFile opens a file, transparently decompressing gzip.
+func openFile(filename string) (io.ReadCloser, error) {
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-
-	var items []FreeContentItem
-	if err := json.NewDecoder(f).Decode(&items); err != nil {
-		return nil, err
+	if !strings.HasSuffix(filename, ".gz") {
+		return f, nil
 	}
-
-	for _, item := range items {
-		key := fmt.Sprintf("%s:%s", item.Sid, item.MegaCollection)
-		switch strings.TrimSpace(strings.ToLower(item.FreeContent)) {
-		case "ja", "yes", "ok", "1", "T", "true":
-			lookup[key] = true
-		case "nicht festgelegt":
-			lookup[key] = false
-		default:
-			lookup[key] = false
-		}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
 	}
-	return lookup, nil
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, f}, nil
 }
 
 // kbartToFilterConfig creates map that can be serialized into a valid filterconfig JSON.
@@ -76,10 +64,15 @@ var (
 	showVersion      = flag.Bool("v", false, "prints current program version")
 	kbartFile        = flag.String("f", "", "path to a single KBART file")
 	freeContentFile  = flag.String("fc", "", "path to a .../list?do=freeContent AMSL response JSON file")
+	doajFile         = flag.String("doaj", "", "path to DOAJ journal CSV or ISSN list")
+	doiFile          = flag.String("dois", "", "path to open access DOI list or Unpaywall snapshot (JSON lines), may be gzipped")
+	checkLicense     = flag.Bool("license", false, "set x.oa for records with a Creative Commons or public domain license")
 	batchsize        = flag.Int("b", 5000, "batch size")
 	verbose          = flag.Bool("verbose", false, "extended output")
 	batchMemoryLimit = flag.Int64("m", 209715200, "memory limit per batch")
 	bestEffort       = flag.Bool("B", false, "ignore unmarshaling errors")
+	holdingsCache    = flag.Bool("hc", false, "keep parsed holdings files in an on-disk cache across runs")
+	holdingsCacheDir = flag.String("hc-dir", filter.DefaultCacheDir(), "holdings cache directory")
 )
 
 func main() {
@@ -99,40 +92,86 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Prepare filterconfig.
-	fmap, err := kbartToFilterConfig(*kbartFile, *verbose)
-	if err != nil {
-		log.Fatal(err)
+	if *holdingsCache {
+		filter.CacheDir = *holdingsCacheDir
 	}
 
-	config, err := json.Marshal(fmap)
-	if err != nil {
-		log.Fatal(err)
+	// Resolvers are asked in order, the first one that knows the record
+	// decides. Source lists come first, free content information may
+	// override KBART, refs #12738.
+	openAccessSids := &openaccess.SourceList{Status: openaccess.Open, Sources: make(map[string]bool)}
+	for _, sid := range openAccessSourceIdentifiersFlags {
+		openAccessSids.Sources[sid] = true
 	}
-
-	// Create a holdings filter, fail here, if files are broken.
-	filter := filter.HoldingsFilter{}
-	if err := filter.UnmarshalJSON(config); err != nil {
-		log.Fatal(err)
+	excludeSids := &openaccess.SourceList{Status: openaccess.Closed, Sources: make(map[string]bool)}
+	for _, sid := range excludeSourceIdentifiersFlags {
+		excludeSids.Sources[sid] = true
 	}
+	chain := openaccess.Chain{openAccessSids, excludeSids}
 
-	lookup := make(FreeContentLookup)
 	if *freeContentFile != "" {
-		lookup, err = createFreeContentLookup(*freeContentFile)
+		f, err := os.Open(*freeContentFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fc, err := openaccess.ReadFreeContent(f)
+		f.Close()
 		if err != nil {
 			log.Fatal(err)
 		}
-		log.Printf("loaded free content map with %d entries", len(lookup))
+		log.Printf("loaded free content map with %d entries", len(fc))
+		chain = append(chain, fc)
 	}
 
-	excludeSids := make(map[string]bool)
-	for _, sid := range excludeSourceIdentifiersFlags {
-		excludeSids[sid] = true
+	if *kbartFile != "" {
+		// Prepare filterconfig.
+		fmap, err := kbartToFilterConfig(*kbartFile, *verbose)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config, err := json.Marshal(fmap)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// Create a holdings filter, fail here, if files are broken.
+		var hf filter.HoldingsFilter
+		if err := hf.UnmarshalJSON(config); err != nil {
+			log.Fatal(err)
+		}
+		chain = append(chain, &openaccess.FilterResolver{Name: filepath.Base(*kbartFile), Filter: &hf})
 	}
 
-	openAccessSids := make(map[string]bool)
-	for _, sid := range openAccessSourceIdentifiersFlags {
-		openAccessSids[sid] = true
+	if *doajFile != "" {
+		f, err := openFile(*doajFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		doaj, err := openaccess.ReadDOAJ(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("loaded %d ISSN from DOAJ list", len(doaj))
+		chain = append(chain, doaj)
+	}
+
+	if *checkLicense {
+		chain = append(chain, openaccess.License{})
+	}
+
+	if *doiFile != "" {
+		f, err := openFile(*doiFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		dois, err := openaccess.ReadDOIList(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+		dois.Name = filepath.Base(*doiFile)
+		log.Printf("loaded %d open access DOI", dois.Len())
+		chain = append(chain, dois)
 	}
 
 	w := bufio.NewWriter(os.Stdout)
@@ -149,29 +188,7 @@ func main() {
 			}
 		}
 
-		if _, ok := openAccessSids[is.SourceID]; ok {
-			is.OpenAccess = true
-		} else {
-			// Bail out on excluded SIDs, refs #12738.
-			if _, ok := excludeSids[is.SourceID]; !ok {
-
-				// Set OA by KBART: various list (e.g. KBART in AMSL, OA GOLD list, maybe more in this format).
-				if filter.Apply(is) {
-					is.OpenAccess = true
-				}
-
-				// Additionally, compare free content API results.
-				for _, c := range is.MegaCollections {
-					key := fmt.Sprintf("%s:%s", is.SourceID, c)
-					if v, ok := lookup[key]; ok {
-						is.OpenAccess = v
-						if v {
-							break // In case of multiple collections, we keep the max.
-						}
-					}
-				}
-			}
-		}
+		chain.Apply(&is)
 
 		bb, err := json.Marshal(is)
 		if err != nil {
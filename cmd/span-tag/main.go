@@ -4,6 +4,16 @@
 //
 // $ span-tag -c '{"DE-15": {"any": {}}}' < input.ldj > output.ldj
 //
+// To find out, why a label was attached or not, write a decision trace per
+// record into a sidecar file:
+//
+// $ span-tag -c filterconfig.json -explain trace.ndjson < input.ldj > output.ldj
+//
+// To check a filterconfig for likely mistakes, optionally cross-checking
+// collection and source values against an index:
+//
+// $ span-tag -c filterconfig.json -lint [-server localhost:8983/solr/biblio]
+//
 // FincClassFacet: https://git.sc.uni-leipzig.de/ubl/finc/fincmarcimport
 package main
 
@@ -20,11 +30,13 @@ import (
 	"runtime/pprof"
 	"slices"
 	"strings"
+	"sync"
 
 	json "github.com/segmentio/encoding/json"
 	"log"
 
 	"github.com/miku/span"
+	"github.com/miku/span/container"
 	"github.com/miku/span/filter"
 	"github.com/miku/span/freeze"
 	"github.com/miku/span/formats/finc"
@@ -51,8 +63,20 @@ var (
 	ignoreSameIdentifier = flag.Bool("isi", false, "when doing deduplication, ignore matches in index with the same id")
 	dropDangling         = flag.Bool("D", false, "drop dangling documents that do not have any isil attached")
 	expand               = flag.String("expand", "", "JSON file mapping meta-ISILs to lists of ISILs to expand into")
+	explain              = flag.String("explain", "", "write decision trace per record and label as NDJSON to this file")
+	lint                 = flag.Bool("lint", false, "check filterconfig for likely mistakes and exit, with -server check collections and sources against index")
+	holdingsCache        = flag.Bool("hc", false, "keep parsed holdings files in an on-disk cache across runs")
+	holdingsCacheDir     = flag.String("hc-dir", filter.DefaultCacheDir(), "holdings cache directory")
+	holdingsCachePrune   = flag.Duration("hc-prune", 0, "remove cached holdings not used for this long, e.g. 720h")
 )
 
+// Explanation is a single line in the explain sidecar file.
+type Explanation struct {
+	ID     string                  `json:"finc.id"`
+	Labels []string                `json:"x.labels"`
+	Traces map[string]filter.Trace `json:"traces"`
+}
+
 // SelectResponse with reduced fields.
 type SelectResponse struct {
 	Response struct {
@@ -167,6 +191,16 @@ func main() {
 	if *server != "" {
 		*server = solrutil.PrependHTTP(*server)
 	}
+	if *holdingsCache {
+		if *holdingsCachePrune > 0 {
+			n, err := filter.PruneCacheDir(*holdingsCacheDir, *holdingsCachePrune)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("[span-tag] pruned %d cached holdings files", n)
+		}
+		filter.CacheDir = *holdingsCacheDir
+	}
 	var (
 		// The configuration forest.
 		tagger filter.Tagger
@@ -208,6 +242,39 @@ func main() {
 		tagger.Expand(rules)
 		log.Printf("[span-tag] expanded %d meta-ISIL(s)", len(rules))
 	}
+	if *lint {
+		var opts filter.LintOptions
+		if *server != "" {
+			index := solrutil.Index{Server: *server}
+			collections, err := index.FacetKeys("*:*", "mega_collection")
+			if err != nil {
+				log.Fatal(err)
+			}
+			sources, err := index.SourceIdentifiers()
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts.Collections = container.NewStringSet(collections...)
+			opts.Sources = container.NewStringSet(sources...)
+		}
+		var numErrors int
+		for _, issue := range tagger.Lint(opts) {
+			fmt.Println(issue)
+			if issue.Severity == filter.SeverityError {
+				numErrors++
+			}
+		}
+		if numErrors > 0 {
+			log.Fatalf("[span-tag] %d error(s)", numErrors)
+		}
+		os.Exit(0)
+	}
+	// Shared subexpressions are evaluated once per record.
+	evaluator, err := tagger.Compile()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("[span-tag] compiled %d labels into %d distinct filters", len(tagger.FilterMap), evaluator.Size())
 	w := bufio.NewWriter(os.Stdout)
 	defer w.Flush()
 	if flag.NArg() > 0 {
@@ -222,13 +289,44 @@ func main() {
 		}
 		reader = io.MultiReader(files...)
 	}
+	var (
+		ew *bufio.Writer // Sidecar writer for decision traces.
+		mu sync.Mutex
+	)
+	if *explain != "" {
+		f, err := os.Create(*explain)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		ew = bufio.NewWriter(f)
+		defer ew.Flush()
+	}
 	// Processing function, tagging documents.
 	procfunc := func(_ int64, b []byte) ([]byte, error) {
-		var is finc.IntermediateSchema
+		var (
+			is     finc.IntermediateSchema
+			tagged finc.IntermediateSchema
+			traces map[string]filter.Trace
+		)
 		if err := json.Unmarshal(b, &is); err != nil {
 			return b, err
 		}
-		tagged := tagger.Tag(is)
+		if ew == nil {
+			tagged = evaluator.Tag(is)
+		} else {
+			tagged, traces = tagger.TagExplain(is)
+			eb, err := json.Marshal(Explanation{ID: tagged.ID, Labels: tagged.Labels, Traces: traces})
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			_, err = ew.Write(append(eb, '\n'))
+			mu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+		}
 		// We can save some space in the index, when we drop records w/o any
 		// isil attached.
 		if *dropDangling && len(tagged.Labels) == 0 {
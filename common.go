@@ -24,7 +24,10 @@ package span
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 )
 
 // AppVersion of span package. Commandline tools will show this on -v.
@@ -38,6 +41,13 @@ const (
 	KeyLengthLimit = 250
 )
 
+// ErrNoSourceID is returned by formats without a fixed source id, if a record
+// is converted before a source id is set, e.g. with span-import -sid.
+var ErrNoSourceID = errors.New("source id is missing")
+
+// doiPattern finds a DOI, e.g. in a link.
+var doiPattern = regexp.MustCompile(`10\.[0-9]{4,9}/[^\s]+`)
+
 // Skip marks records to skip.
 type Skip struct {
 	Reason string
@@ -55,3 +65,10 @@ func GenFincID(sid, rid string) string {
 	return fmt.Sprintf("ai-%s-%s", sid,
 		base64.RawURLEncoding.EncodeToString([]byte(rid)))
 }
+
+// FindDOI returns the first DOI in a string, e.g. in an identifier field or a
+// link, or the empty string. A trailing period is not considered part of the
+// DOI.
+func FindDOI(s string) string {
+	return strings.TrimRight(doiPattern.FindString(s), ".")
+}
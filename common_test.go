@@ -25,3 +25,23 @@ func TestGenFincID(t *testing.T) {
 		})
 	}
 }
+
+func TestFindDOI(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"", ""},
+		{"10.1000/xyz.123", "10.1000/xyz.123"},
+		{"https://doi.org/10.1000/xyz.123", "10.1000/xyz.123"},
+		{"doi:10.1000/xyz.123.", "10.1000/xyz.123"},
+		{"10.1000/a b", "10.1000/a"},
+		{"10.1/x", ""},
+		{"no doi", ""},
+	}
+	for _, c := range cases {
+		if got := FindDOI(c.s); got != c.result {
+			t.Errorf("FindDOI(%q): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
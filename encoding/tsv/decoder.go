@@ -59,7 +59,12 @@ func (dec *Decoder) Decode(v any) error {
 	}
 	line, err := dec.r.ReadString('\n')
 	if err == io.EOF {
-		return io.EOF
+		// The last line may not end with a newline. Leading tabs are kept,
+		// since they separate empty columns.
+		if strings.TrimSpace(line) == "" {
+			return io.EOF
+		}
+		line = strings.TrimRight(line, "\r\n")
 	}
 	record := strings.Split(line, dec.Separator)
 
@@ -1,6 +1,7 @@
 package tsv
 
 import (
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -155,6 +156,51 @@ func TestDecodeSingleColumnKbart(t *testing.T) {
 	}
 }
 
+func TestDecodeNoTrailingNewline(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(strings.TrimSpace(testOne)))
+
+	var example TestSimple
+	if err := dec.Decode(&example); err != nil {
+		t.Error(err.Error())
+	}
+
+	expected := TestSimple{Title: "Hello", ID: "123"}
+	if !reflect.DeepEqual(example, expected) {
+		t.Errorf("Decode: got %#v, want %#v", example, expected)
+	}
+}
+
+func TestDecodeEmptyFirstColumn(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result []TestSimple
+	}{
+		{"publication_title\tprint_identifier\nx\ty\n\tz", []TestSimple{{"x", "y"}, {"", "z"}}},
+		{"publication_title\tprint_identifier\nx\ty\n\tz\n", []TestSimple{{"x", "y"}, {"", "z"}}},
+		{"publication_title\tprint_identifier\r\n\tz\r\n\tw", []TestSimple{{"", "z"}, {"", "w"}}},
+	}
+	for _, c := range cases {
+		var (
+			dec    = NewDecoder(strings.NewReader(c.s))
+			result []TestSimple
+		)
+		for {
+			var example TestSimple
+			err := dec.Decode(&example)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, example)
+		}
+		if !reflect.DeepEqual(result, c.result) {
+			t.Errorf("Decode(%q): got %#v, want %#v", c.s, result, c.result)
+		}
+	}
+}
+
 func BenchmarkDecodeKbart(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		dec := NewDecoder(strings.NewReader(testTwo))
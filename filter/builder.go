@@ -77,6 +77,64 @@ func ISBN(isbns ...string) *ISBNFilter {
 	return &ISBNFilter{Values: container.NewStringSet(isbns...)}
 }
 
+// Date creates a filter matching records published between from and to,
+// inclusive. Bounds are given as YYYY, YYYY-MM or YYYY-MM-DD, an empty bound
+// is unconstrained. Date panics, if a bound cannot be parsed.
+func Date(from, to string) *DateFilter {
+	f := &DateFilter{From: from, To: to}
+	if err := f.parseBounds(); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Language creates a filter matching records in any of the given languages.
+// Values are normalized to ISO 639-3 like in a filterconfig, e.g. "ger" to
+// "deu".
+func Language(langs ...string) *LanguageFilter {
+	return &LanguageFilter{Values: normalizeLanguages(langs)}
+}
+
+// License creates a filter matching records with any of the given licenses,
+// e.g. "CC-BY-4.0" or "CC-BY" for any version. Values are normalized like in
+// a filterconfig.
+func License(ids ...string) *LicenseFilter {
+	return &LicenseFilter{Values: normalizeLicenses(ids)}
+}
+
+// Format creates a filter matching records with any of the given formats.
+func Format(formats ...string) *FormatFilter {
+	return &FormatFilter{Values: container.NewStringSet(formats...)}
+}
+
+// FieldExact creates a filter matching records, where the named field equals
+// any of the given values. It panics, if the field is not supported.
+func FieldExact(name string, values ...string) *FieldFilter {
+	return mustField(&FieldFilter{Name: name, Exact: values})
+}
+
+// FieldPrefix creates a filter matching records, where the named field
+// starts with any of the given prefixes. It panics, if the field is not
+// supported.
+func FieldPrefix(name string, prefixes ...string) *FieldFilter {
+	return mustField(&FieldFilter{Name: name, Prefix: prefixes})
+}
+
+// FieldRegex creates a filter matching records, where the named field
+// matches any of the given regular expressions. It panics, if the field is
+// not supported or an expression does not compile.
+func FieldRegex(name string, exprs ...string) *FieldFilter {
+	return mustField(&FieldFilter{Name: name, Regex: exprs})
+}
+
+// mustField compiles a field filter or panics.
+func mustField(f *FieldFilter) *FieldFilter {
+	if err := f.compile(); err != nil {
+		panic(err)
+	}
+	return f
+}
+
 // Holdings creates a holdings filter from file paths and/or URLs. The actual
 // KBART data is not loaded; this constructor is meant for building a tree that
 // will be serialized to JSON configuration.
@@ -121,15 +179,50 @@ func (f *SourceFilter) MarshalJSON() ([]byte, error) {
 }
 
 func (f *CollectionFilter) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string][]string{"collection": f.Values.Values()})
+	return json.Marshal(map[string][]string{"collection": f.Values.SortedValues()})
 }
 
 func (f *SubjectFilter) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string][]string{"subject": f.Values.Values()})
+	return json.Marshal(map[string][]string{"subject": f.Values.SortedValues()})
 }
 
 func (f *PackageFilter) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string][]string{"package": f.Values.Values()})
+	return json.Marshal(map[string][]string{"package": f.Values.SortedValues()})
+}
+
+func (f *LanguageFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]string{"language": f.Values.SortedValues()})
+}
+
+func (f *LicenseFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]string{"license": f.Values.SortedValues()})
+}
+
+func (f *FormatFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]string{"format": f.Values.SortedValues()})
+}
+
+func (f *DateFilter) MarshalJSON() ([]byte, error) {
+	type date struct {
+		From string `json:"from,omitempty"`
+		To   string `json:"to,omitempty"`
+	}
+	return json.Marshal(map[string]date{"date": {From: f.From, To: f.To}})
+}
+
+func (f *FieldFilter) MarshalJSON() ([]byte, error) {
+	type field struct {
+		Name   string   `json:"name"`
+		Exact  []string `json:"exact,omitempty"`
+		Prefix []string `json:"prefix,omitempty"`
+		Regex  []string `json:"regex,omitempty"`
+	}
+	return json.Marshal(map[string]field{"field": {
+		Name:   f.Name,
+		Exact:  f.Exact,
+		Prefix: f.Prefix,
+		Regex:  f.Regex,
+	}})
 }
 
 func (f *DOIFilter) MarshalJSON() ([]byte, error) {
@@ -152,7 +245,7 @@ func (f *ISSNFilter) MarshalJSON() ([]byte, error) {
 	}{
 		ISSN: struct {
 			List []string `json:"list"`
-		}{List: f.Values.Values()},
+		}{List: f.Values.SortedValues()},
 	})
 }
 
@@ -164,7 +257,7 @@ func (f *ISBNFilter) MarshalJSON() ([]byte, error) {
 	}{
 		ISBN: struct {
 			List []string `json:"list"`
-		}{List: f.Values.Values()},
+		}{List: f.Values.SortedValues()},
 	})
 }
 
@@ -195,6 +288,7 @@ func (f *HoldingsFilter) MarshalJSON() ([]byte, error) {
 			URLs           []string `json:"urls,omitempty"`
 			Verbose        bool     `json:"verbose,omitempty"`
 			CompareByTitle bool     `json:"compare-by-title,omitempty"`
+			TitleThreshold float64  `json:"title-threshold,omitempty"`
 		} `json:"holdings"`
 	}{
 		Holdings: struct {
@@ -202,11 +296,13 @@ func (f *HoldingsFilter) MarshalJSON() ([]byte, error) {
 			URLs           []string `json:"urls,omitempty"`
 			Verbose        bool     `json:"verbose,omitempty"`
 			CompareByTitle bool     `json:"compare-by-title,omitempty"`
+			TitleThreshold float64  `json:"title-threshold,omitempty"`
 		}{
 			Files:          files,
 			URLs:           urls,
 			Verbose:        f.Verbose,
 			CompareByTitle: f.CompareByTitle,
+			TitleThreshold: f.TitleThreshold,
 		},
 	})
 }
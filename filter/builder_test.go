@@ -215,3 +215,51 @@ func TestMarshalHoldingsFilter(t *testing.T) {
 		t.Errorf("expected urls=[https://example.com/kbart], got %v", urls)
 	}
 }
+
+func TestBuilderRecordFiltersRoundTrip(t *testing.T) {
+	tree := NewTree(And(
+		Date("2000", "2010-06"),
+		Language("eng", "ger"),
+		Format("ElectronicArticle"),
+		FieldPrefix("rft.jtitle", "Journal of"),
+	))
+	b, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got Tree
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+	match := finc.IntermediateSchema{
+		RawDate:      "2010-06-30",
+		Languages:    []string{"deu"},
+		Format:       "ElectronicArticle",
+		JournalTitle: "Journal of Things",
+	}
+	var tests = []struct {
+		about  string
+		record func(finc.IntermediateSchema) finc.IntermediateSchema
+		result bool
+	}{
+		{"all match", func(is finc.IntermediateSchema) finc.IntermediateSchema { return is }, true},
+		{"date too late", func(is finc.IntermediateSchema) finc.IntermediateSchema { is.RawDate = "2010-07-01"; return is }, false},
+		{"language", func(is finc.IntermediateSchema) finc.IntermediateSchema { is.Languages = []string{"fra"}; return is }, false},
+		{"format", func(is finc.IntermediateSchema) finc.IntermediateSchema { is.Format = "eBook"; return is }, false},
+		{"title", func(is finc.IntermediateSchema) finc.IntermediateSchema {
+			is.JournalTitle = "Annals of Things"
+			return is
+		}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			record := test.record(match)
+			if result := tree.Apply(record); result != test.result {
+				t.Errorf("original Apply got %v, want %v", result, test.result)
+			}
+			if result := got.Apply(record); result != test.result {
+				t.Errorf("roundtripped Apply got %v, want %v", result, test.result)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/formats/finc"
+)
+
+// nodeKind distinguishes leaf filters from logic filters in a compiled tree.
+type nodeKind byte
+
+const (
+	leafNode nodeKind = iota
+	andNode
+	orNode
+	notNode
+)
+
+// node is a single, deduplicated filter node. Logic nodes refer to their
+// children by index.
+type node struct {
+	kind     nodeKind
+	leaf     Filter
+	children []int
+}
+
+// Evaluator is a compiled Tagger. All trees of a tagger are merged into a
+// single graph, in which equal subexpressions, e.g. the same holdings or
+// collection filter used by hundreds of labels, occur only once. Each node is
+// evaluated at most once per record. An Evaluator is safe for concurrent use.
+type Evaluator struct {
+	nodes  []node
+	labels []string // sorted
+	roots  []int    // root node per label
+}
+
+// compiler assigns node ids to filters by structural key.
+type compiler struct {
+	nodes []node
+	ids   map[string]int
+}
+
+// add registers a node under a key, unless a node with that key exists.
+func (c *compiler) add(key string, n node) int {
+	if id, ok := c.ids[key]; ok {
+		return id
+	}
+	c.nodes = append(c.nodes, n)
+	id := len(c.nodes) - 1
+	c.ids[key] = id
+	return id
+}
+
+// children compiles a list of filters.
+func (c *compiler) children(filters []Filter) ([]int, string, error) {
+	var (
+		ids  []int
+		keys []string
+	)
+	for _, f := range filters {
+		id, err := c.compile(f)
+		if err != nil {
+			return nil, "", err
+		}
+		ids = append(ids, id)
+		keys = append(keys, fmt.Sprintf("%d", id))
+	}
+	return ids, strings.Join(keys, ","), nil
+}
+
+// compile returns the node id for a filter. Leaf filters are considered equal,
+// if their JSON serializations are equal, which is why only filters
+// implementing json.Marshaler are shared. Other filters get a node of their own.
+func (c *compiler) compile(f Filter) (int, error) {
+	switch v := f.(type) {
+	case *AndFilter:
+		ids, key, err := c.children(v.Filters)
+		if err != nil {
+			return 0, err
+		}
+		return c.add("and("+key+")", node{kind: andNode, children: ids}), nil
+	case *OrFilter:
+		ids, key, err := c.children(v.Filters)
+		if err != nil {
+			return 0, err
+		}
+		return c.add("or("+key+")", node{kind: orNode, children: ids}), nil
+	case *NotFilter:
+		ids, key, err := c.children([]Filter{v.Filter})
+		if err != nil {
+			return 0, err
+		}
+		return c.add("not("+key+")", node{kind: notNode, children: ids}), nil
+	case json.Marshaler:
+		b, err := v.MarshalJSON()
+		if err != nil {
+			return 0, err
+		}
+		return c.add(fmt.Sprintf("%T:%s", f, b), node{kind: leafNode, leaf: f}), nil
+	default:
+		return c.add(fmt.Sprintf("%p", f), node{kind: leafNode, leaf: f}), nil
+	}
+}
+
+// Compile turns the filter trees of a tagger into an Evaluator, which
+// attaches the same labels as Tag, but evaluates shared subexpressions only
+// once per record.
+func (t *Tagger) Compile() (*Evaluator, error) {
+	c := &compiler{ids: make(map[string]int)}
+	e := &Evaluator{}
+	for label := range t.FilterMap {
+		e.labels = append(e.labels, label)
+	}
+	slices.Sort(e.labels)
+	for _, label := range e.labels {
+		tree := t.FilterMap[label]
+		if tree.Root == nil {
+			return nil, fmt.Errorf("compile: empty tree for %s", label)
+		}
+		id, err := c.compile(tree.Root)
+		if err != nil {
+			return nil, fmt.Errorf("compile: %s: %w", label, err)
+		}
+		e.roots = append(e.roots, id)
+	}
+	e.nodes = c.nodes
+	return e, nil
+}
+
+// Size returns the number of distinct nodes in the compiled graph.
+func (e *Evaluator) Size() int {
+	return len(e.nodes)
+}
+
+// Memo values, zero means not evaluated yet.
+const (
+	memoFalse byte = iota + 1
+	memoTrue
+)
+
+// eval evaluates a node with short circuit semantics, results are memoized
+// for the current record.
+func (e *Evaluator) eval(id int, is *finc.IntermediateSchema, memo []byte) bool {
+	if m := memo[id]; m != 0 {
+		return m == memoTrue
+	}
+	var (
+		n      = e.nodes[id]
+		result bool
+	)
+	switch n.kind {
+	case andNode:
+		result = true
+		for _, c := range n.children {
+			if !e.eval(c, is, memo) {
+				result = false
+				break
+			}
+		}
+	case orNode:
+		for _, c := range n.children {
+			if e.eval(c, is, memo) {
+				result = true
+				break
+			}
+		}
+	case notNode:
+		result = !e.eval(n.children[0], is, memo)
+	default:
+		result = n.leaf.Apply(*is)
+	}
+	if result {
+		memo[id] = memoTrue
+	} else {
+		memo[id] = memoFalse
+	}
+	return result
+}
+
+// Tag returns a labeled version of the record, labels are sorted.
+func (e *Evaluator) Tag(is finc.IntermediateSchema) finc.IntermediateSchema {
+	memo := make([]byte, len(e.nodes))
+	for i, root := range e.roots {
+		if e.eval(root, &is, memo) {
+			is.Labels = append(is.Labels, e.labels[i])
+		}
+	}
+	return is
+}
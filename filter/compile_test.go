@@ -0,0 +1,184 @@
+package filter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing/kbart"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestCompile(t *testing.T) {
+	s := `{
+		"DE-1": {"and": [{"source": ["49"]}, {"collection": ["A", "B"]}]},
+		"DE-2": {"and": [{"source": ["49"]}, {"collection": ["B", "A"]}]},
+		"DE-3": {"or": [{"source": ["55"]}, {"not": {"collection": ["A", "B"]}}]},
+		"DE-4": {"any": {}},
+		"DE-5": {"and": [{"source": ["49"]}, {"date": {"from": "2000"}}]}
+	}`
+	var tagger Tagger
+	if err := json.Unmarshal([]byte(s), &tagger); err != nil {
+		t.Fatal(err)
+	}
+	e, err := tagger.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// source 49, collection A/B, and, or, source 55, not, any, date, and
+	if e.Size() != 9 {
+		t.Errorf("got %d nodes, want 9", e.Size())
+	}
+	var records = []finc.IntermediateSchema{
+		{SourceID: "49", MegaCollections: []string{"A"}, RawDate: "2001"},
+		{SourceID: "49", MegaCollections: []string{"C"}, RawDate: "1999"},
+		{SourceID: "55", MegaCollections: []string{"B"}},
+		{SourceID: "1"},
+	}
+	for i, is := range records {
+		t.Run(fmt.Sprintf("record-%d", i), func(t *testing.T) {
+			want := tagger.Tag(is).Labels
+			got := e.Tag(is).Labels
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// loadBenchmarkHoldings loads the KBART fixture into the holdings cache under
+// the given key.
+func loadBenchmarkHoldings(b *testing.B, key string) kbart.Holdings {
+	f, err := os.Open("../fixtures/kbart.txt.gz")
+	if err != nil {
+		b.Skipf("fixture: %v", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		b.Skipf("fixture: %v", err)
+	}
+	var h kbart.Holdings
+	if _, err := h.ReadFrom(bufio.NewReader(zr)); err != nil {
+		b.Skipf("fixture: %v", err)
+	}
+	Cache[key] = NewCacheValue(h)
+	return h
+}
+
+// benchmarkRecords derives records from holdings entries.
+func benchmarkRecords(h kbart.Holdings, n int) (records []finc.IntermediateSchema) {
+	for i := 0; i < len(h) && len(records) < n; i += 7 {
+		issns := h[i].ISSNList()
+		if len(issns) == 0 {
+			continue
+		}
+		records = append(records, finc.IntermediateSchema{
+			SourceID:        []string{"49", "55", "48"}[i%3],
+			ISSN:            issns,
+			RawDate:         "2010-01-01",
+			MegaCollections: []string{fmt.Sprintf("Collection %d", i%20)},
+		})
+	}
+	return records
+}
+
+// benchmarkTagger resembles a generated filterconfig: many labels share the
+// same holdings file and collection lists.
+func benchmarkTagger(key string, labels int) *Tagger {
+	var collections []string
+	for i := 0; i < 20; i++ {
+		collections = append(collections, fmt.Sprintf("Collection %d", i))
+	}
+	tagger := NewTagger()
+	for i := 0; i < labels; i++ {
+		h := &HoldingsFilter{Names: []string{key}}
+		tagger.Add(fmt.Sprintf("DE-%d", i), Or(
+			And(Source("49"), h, Collection(collections...)),
+			And(Source("55"), h),
+			And(Source("48"), Collection(collections[i%20])),
+		))
+	}
+	return tagger
+}
+
+func BenchmarkTag(b *testing.B) {
+	const key = "benchmark-kbart"
+	h := loadBenchmarkHoldings(b, key)
+	defer delete(Cache, key)
+	var (
+		records = benchmarkRecords(h, 1000)
+		tagger  = benchmarkTagger(key, 300)
+	)
+	e, err := tagger.Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("tagger", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = tagger.Tag(records[i%len(records)])
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = e.Tag(records[i%len(records)])
+		}
+	})
+}
+
+// BenchmarkTagFrozen runs against a frozen filterconfig (from span-freeze)
+// and an intermediate schema sample, e.g.
+//
+//	$ SPAN_BENCH_FROZEN=frozen.zip SPAN_BENCH_SAMPLE=sample.is go test -bench TagFrozen
+func BenchmarkTagFrozen(b *testing.B) {
+	frozen, sample := os.Getenv("SPAN_BENCH_FROZEN"), os.Getenv("SPAN_BENCH_SAMPLE")
+	if frozen == "" || sample == "" {
+		b.Skip("set SPAN_BENCH_FROZEN and SPAN_BENCH_SAMPLE to run")
+	}
+	tagger, err := ReadTagger(frozen)
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.Open(sample)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	var (
+		records []finc.IntermediateSchema
+		dec     = json.NewDecoder(bufio.NewReader(f))
+	)
+	for {
+		var is finc.IntermediateSchema
+		if err := dec.Decode(&is); err == io.EOF {
+			break
+		} else if err != nil {
+			b.Fatal(err)
+		}
+		records = append(records, is)
+	}
+	if len(records) == 0 {
+		b.Skip("empty sample")
+	}
+	e, err := tagger.Compile()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Logf("%d labels, %d distinct nodes", len(tagger.FilterMap), e.Size())
+	b.Run("tagger", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = tagger.Tag(records[i%len(records)])
+		}
+	})
+	b.Run("compiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = e.Tag(records[i%len(records)])
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/formats/finc"
+)
+
+// dateLayouts are the accepted layouts for date bounds, coarsest first.
+var dateLayouts = []string{"2006", "2006-01", "2006-01-02"}
+
+// DateFilter allows records published within a date range. Both bounds are
+// optional and inclusive. They may be given as year, month or day, so "to":
+// "2010" includes all of 2010. Records without a date are not matched.
+//
+//	{"date": {"from": "2000", "to": "2010-06"}}
+type DateFilter struct {
+	From string
+	To   string
+
+	from time.Time // inclusive, zero means unbounded
+	to   time.Time // exclusive, zero means unbounded
+}
+
+// parseBounds parses the textual bounds of the filter.
+func (f *DateFilter) parseBounds() (err error) {
+	if f.From != "" {
+		if f.from, _, err = parseDateBound(f.From); err != nil {
+			return err
+		}
+	}
+	if f.To != "" {
+		t, layout, err := parseDateBound(f.To)
+		if err != nil {
+			return err
+		}
+		switch layout {
+		case "2006":
+			f.to = t.AddDate(1, 0, 0)
+		case "2006-01":
+			f.to = t.AddDate(0, 1, 0)
+		default:
+			f.to = t.AddDate(0, 0, 1)
+		}
+	}
+	if !f.from.IsZero() && !f.to.IsZero() && !f.from.Before(f.to) {
+		return fmt.Errorf("date: empty range from %s to %s", f.From, f.To)
+	}
+	return nil
+}
+
+// parseDateBound parses a year, month or day and returns the matching layout.
+func parseDateBound(s string) (time.Time, string, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, layout, nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("date: cannot parse %q, want YYYY, YYYY-MM or YYYY-MM-DD", s)
+}
+
+// recordDate returns the publication date of a record, falling back to the
+// raw date, if the parsed date is missing.
+func recordDate(is finc.IntermediateSchema) (time.Time, bool) {
+	if !is.Date.IsZero() {
+		return is.Date, true
+	}
+	s := is.RawDate
+	if len(s) > 10 {
+		s = s[:10]
+	}
+	t, _, err := parseDateBound(s)
+	return t, err == nil
+}
+
+// Apply filter.
+func (f *DateFilter) Apply(is finc.IntermediateSchema) bool {
+	t, ok := recordDate(is)
+	if !ok {
+		return false
+	}
+	if !f.from.IsZero() && t.Before(f.from) {
+		return false
+	}
+	if !f.to.IsZero() && !t.Before(f.to) {
+		return false
+	}
+	return true
+}
+
+// UnmarshalJSON turns a config fragment into a filter.
+func (f *DateFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Date struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		} `json:"date"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.From, f.To = s.Date.From, s.Date.To
+	return f.parseBounds()
+}
@@ -0,0 +1,52 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestDateFilter(t *testing.T) {
+	var tests = []struct {
+		about  string
+		config string
+		record finc.IntermediateSchema
+		result bool
+	}{
+		{"from only, inside", `{"date": {"from": "2000"}}`, finc.IntermediateSchema{RawDate: "2000-01-01"}, true},
+		{"from only, before", `{"date": {"from": "2000"}}`, finc.IntermediateSchema{RawDate: "1999-12-31"}, false},
+		{"to year is inclusive", `{"date": {"to": "2010"}}`, finc.IntermediateSchema{RawDate: "2010-12-31"}, true},
+		{"to year, after", `{"date": {"to": "2010"}}`, finc.IntermediateSchema{RawDate: "2011-01-01"}, false},
+		{"to month is inclusive", `{"date": {"to": "2010-02"}}`, finc.IntermediateSchema{RawDate: "2010-02-28"}, true},
+		{"to day is inclusive", `{"date": {"to": "2010-02-01"}}`, finc.IntermediateSchema{RawDate: "2010-02-01"}, true},
+		{"parsed date wins", `{"date": {"from": "2000"}}`,
+			finc.IntermediateSchema{RawDate: "1999", Date: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{"raw date with time", `{"date": {"from": "2000"}}`, finc.IntermediateSchema{RawDate: "2000-05-01T00:00:00Z"}, true},
+		{"no date", `{"date": {"from": "2000"}}`, finc.IntermediateSchema{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			var tree Tree
+			if err := json.Unmarshal([]byte(test.config), &tree); err != nil {
+				t.Fatalf("invalid filter: %s", err)
+			}
+			if result := tree.Apply(test.record); result != test.result {
+				t.Errorf("Apply got %v, want %v", result, test.result)
+			}
+		})
+	}
+}
+
+func TestDateFilterInvalid(t *testing.T) {
+	for _, s := range []string{
+		`{"date": {"from": "yesterday"}}`,
+		`{"date": {"from": "2010", "to": "2000"}}`,
+	} {
+		var tree Tree
+		if err := json.Unmarshal([]byte(s), &tree); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/licensing/kbart"
+)
+
+// Status of a label in a diff.
+type Status string
+
+const (
+	// StatusAdded marks labels only found in the new configuration.
+	StatusAdded Status = "added"
+	// StatusRemoved marks labels only found in the old configuration.
+	StatusRemoved Status = "removed"
+	// StatusChanged marks labels with a different filter tree.
+	StatusChanged Status = "changed"
+)
+
+// LabelDiff describes, how the filter tree of a single label (ISIL) changed
+// between two configurations. Leaf filters are compared by value, e.g.
+// "collection: Foo", holdings filters by their options and the content of
+// their KBART files, so renamed files (e.g. in frozen zips) with the same
+// content yield no change. Holdings changes are per title, refs. kbart.Diff.
+type LabelDiff struct {
+	Label     string         `json:"label"`
+	Status    Status         `json:"status"`
+	Added     []string       `json:"added,omitempty"`
+	Removed   []string       `json:"removed,omitempty"`
+	Structure bool           `json:"structure,omitempty"` // logic operators rearranged
+	Holdings  []kbart.Change `json:"holdings,omitempty"`
+}
+
+// summary collects the comparable parts of a filter tree.
+type summary struct {
+	leaves   *container.StringSet
+	holdings []string // cache keys
+	shape    string
+}
+
+// summarize walks a filter tree.
+func summarize(f Filter) summary {
+	s := summary{leaves: container.NewStringSet()}
+	if f != nil {
+		s.shape = s.walk(f)
+	}
+	return s
+}
+
+// walk records leaves and returns the shape of the tree, which is the tree
+// with leaf values stripped, e.g. "or(and(collection,source),holdings)".
+// Children are sorted, since their order does not matter.
+func (s *summary) walk(f Filter) string {
+	var children []Filter
+	switch v := f.(type) {
+	case *OrFilter:
+		children = v.Filters
+	case *AndFilter:
+		children = v.Filters
+	case *NotFilter:
+		children = []Filter{v.Filter}
+	case *HoldingsFilter:
+		s.holdings = append(s.holdings, v.Names...)
+		if v.CompareByTitle {
+			s.leaves.Add("holdings: compare-by-title")
+		}
+		if v.TitleThreshold > 0 {
+			s.leaves.Add(fmt.Sprintf("holdings: title-threshold %v", v.TitleThreshold))
+		}
+		return filterName(f)
+	default:
+		for _, leaf := range describe(f) {
+			s.leaves.Add(leaf)
+		}
+		return filterName(f)
+	}
+	var shapes []string
+	for _, g := range children {
+		shapes = append(shapes, s.walk(g))
+	}
+	slices.Sort(shapes)
+	return fmt.Sprintf("%s(%s)", filterName(f), strings.Join(shapes, ","))
+}
+
+// describe returns a readable description of a leaf filter, one per value for
+// filters holding a list of values, e.g. "source: 49".
+func describe(f Filter) []string {
+	name := filterName(f)
+	m, ok := f.(json.Marshaler)
+	if !ok {
+		return []string{name}
+	}
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return []string{name}
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(b, &doc); err != nil || len(doc) != 1 {
+		return []string{fmt.Sprintf("%s: %s", name, b)}
+	}
+	for _, raw := range doc {
+		var (
+			values []string
+			list   struct {
+				List []string `json:"list"`
+			}
+		)
+		switch {
+		case json.Unmarshal(raw, &values) == nil:
+		case json.Unmarshal(raw, &list) == nil && list.List != nil:
+			values = list.List
+		default:
+			return []string{fmt.Sprintf("%s: %s", name, raw)}
+		}
+		var result []string
+		for _, v := range values {
+			result = append(result, fmt.Sprintf("%s: %s", name, v))
+		}
+		return result
+	}
+	return nil
+}
+
+// mergeHoldings combines the entries of the cached holdings files with the
+// given names.
+func mergeHoldings(names []string) (h kbart.Holdings) {
+	for _, name := range names {
+		h = append(h, Cache[name].Entries...)
+	}
+	return h
+}
+
+// diffSets returns the values only in b and the values only in a.
+func diffSets(a, b *container.StringSet) (added, removed []string) {
+	for _, v := range b.SortedValues() {
+		if !a.Contains(v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range a.SortedValues() {
+		if !b.Contains(v) {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// Diff compares two taggers label by label and returns the labels, that have
+// been added, removed or changed, sorted by label. The holdings files of both
+// taggers need to be in the cache, which is the case after unmarshaling.
+func Diff(a, b *Tagger) []LabelDiff {
+	labels := container.NewStringSet()
+	for label := range a.FilterMap {
+		labels.Add(label)
+	}
+	for label := range b.FilterMap {
+		labels.Add(label)
+	}
+	var result []LabelDiff
+	for _, label := range labels.SortedValues() {
+		ta, okA := a.FilterMap[label]
+		tb, okB := b.FilterMap[label]
+		var (
+			sa = summarize(ta.Root)
+			sb = summarize(tb.Root)
+			d  = LabelDiff{Label: label, Status: StatusChanged}
+		)
+		switch {
+		case !okA:
+			d.Status = StatusAdded
+		case !okB:
+			d.Status = StatusRemoved
+		}
+		d.Added, d.Removed = diffSets(sa.leaves, sb.leaves)
+		d.Structure = okA && okB && sa.shape != sb.shape
+		d.Holdings = kbart.Diff(mergeHoldings(sa.holdings), mergeHoldings(sb.holdings))
+		if d.Status == StatusChanged && len(d.Added) == 0 && len(d.Removed) == 0 &&
+			!d.Structure && len(d.Holdings) == 0 {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
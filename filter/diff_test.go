@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/miku/span/licensing"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		a = licensing.Entry{PublicationTitle: "A", PrintIdentifier: "1111-1111", FirstIssueDate: "2000"}
+		b = licensing.Entry{PublicationTitle: "B", PrintIdentifier: "2222-2222", FirstIssueDate: "2000"}
+		c = licensing.Entry{PublicationTitle: "C", PrintIdentifier: "3333-3333", FirstIssueDate: "2010"}
+	)
+	// Same content under different names, as in two frozen zips.
+	Cache["diff-old"] = NewCacheValue([]licensing.Entry{a, b})
+	Cache["diff-same"] = Cache["diff-old"]
+	b2 := b
+	b2.Embargo = "P1Y"
+	Cache["diff-new"] = NewCacheValue([]licensing.Entry{b2, c})
+	defer func() {
+		delete(Cache, "diff-old")
+		delete(Cache, "diff-same")
+		delete(Cache, "diff-new")
+	}()
+	oldTagger := NewTagger().
+		Add("DE-1", Or(Source("49"), Collection("X", "Y"))).
+		Add("DE-2", And(Source("49"), Holdings("diff-old"))).
+		Add("DE-3", And(Source("49"), Holdings("diff-old"))).
+		Add("DE-4", Source("1")).
+		Add("DE-6", And(Source("49"), Not(Collection("X")))).
+		Add("DE-7", Holdings("diff-old"))
+	newTagger := NewTagger().
+		Add("DE-1", Or(Collection("Y", "Z"), Source("49"))).
+		Add("DE-2", And(Holdings("diff-same"), Source("49"))).
+		Add("DE-3", And(Source("49"), Holdings("diff-new"))).
+		Add("DE-5", Source("1")).
+		Add("DE-6", Or(Source("49"), Not(Collection("X")))).
+		Add("DE-7", HoldingsWithOpts(false, true, "diff-same"))
+	diffs := Diff(oldTagger, newTagger)
+	var labels []string
+	for _, d := range diffs {
+		labels = append(labels, d.Label)
+	}
+	if want := []string{"DE-1", "DE-3", "DE-4", "DE-5", "DE-6", "DE-7"}; !slices.Equal(labels, want) {
+		t.Fatalf("got %v, want %v", labels, want)
+	}
+	if d := diffs[0]; !slices.Equal(d.Added, []string{"collection: Z"}) ||
+		!slices.Equal(d.Removed, []string{"collection: X"}) || d.Structure {
+		t.Errorf("DE-1: got %+v", d)
+	}
+	var changes []string
+	for _, c := range diffs[1].Holdings {
+		changes = append(changes, fmt.Sprintf("%s %s %s %s", c.Kind, c.Key, c.Old, c.New))
+	}
+	want := []string{
+		"added 3333-3333  2010//-//",
+		"embargo 2222-2222  P1Y",
+		"removed 1111-1111 2000//-// ",
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("DE-3: got %q, want %q", changes, want)
+	}
+	if diffs[2].Status != StatusRemoved || diffs[3].Status != StatusAdded {
+		t.Errorf("got %v and %v", diffs[2].Status, diffs[3].Status)
+	}
+	if d := diffs[4]; !d.Structure || len(d.Added) != 0 || len(d.Removed) != 0 {
+		t.Errorf("DE-6: got %+v", d)
+	}
+	if d := diffs[5]; !slices.Equal(d.Added, []string{"holdings: compare-by-title"}) || len(d.Holdings) != 0 {
+		t.Errorf("DE-7: got %+v", d)
+	}
+}
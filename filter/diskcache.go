@@ -0,0 +1,167 @@
+package filter
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/miku/span/licensing/kbart"
+)
+
+// CacheDir is a directory, where parsed and indexed holdings files are kept
+// across runs. Files are keyed by the SHA1 of their content, so a changed
+// holdings file will be parsed again, while a file that moved to a different
+// location or URL will not. An empty CacheDir disables the on-disk cache.
+// Set it before loading a filter configuration.
+var CacheDir string
+
+// cacheVersion is part of the cache key. Increment it, when the layout of
+// CacheValue or licensing.Entry changes, or when holdings files are parsed
+// differently, e.g. with new header aliases.
+const cacheVersion = 6
+
+// cacheSuffix is the file extension of cached holdings.
+const cacheSuffix = ".holdings.gob.zst"
+
+// tmpPrefix marks cache files, that are still being written.
+const tmpPrefix = ".tmp-"
+
+// tmpMaxAge is the age, after which a temporary file is considered left
+// behind by an aborted run. Younger files may belong to a concurrent writer.
+const tmpMaxAge = 24 * time.Hour
+
+// DefaultCacheDir returns the default holdings cache directory following the
+// XDG Base Directory spec, like the freeze cache.
+func DefaultCacheDir() string {
+	return filepath.Join(xdg.CacheHome, "span", "holdings")
+}
+
+// cachePath returns the path of the cached holdings for a given content.
+func cachePath(dir string, p []byte) string {
+	return filepath.Join(dir, fmt.Sprintf("%x-v%d%s", sha1.Sum(p), cacheVersion, cacheSuffix))
+}
+
+// loadCacheValue reads cached holdings. The modification time of the file is
+// updated, so PruneCacheDir can tell unused files apart.
+func loadCacheValue(path string) (CacheValue, error) {
+	var v CacheValue
+	f, err := os.Open(path)
+	if err != nil {
+		return v, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return v, err
+	}
+	defer zr.Close()
+	if err := gob.NewDecoder(zr).Decode(&v); err != nil {
+		return v, err
+	}
+	// Decoding allocates each string separately.
+	h := kbart.Holdings(v.Entries)
+	h.Intern()
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return v, nil
+}
+
+// saveCacheValue writes holdings to the cache directory. The file is written
+// to a temporary file first and then renamed, so concurrent runs never see a
+// partial file.
+func saveCacheValue(path string, v CacheValue) (err error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, tmpPrefix+"*"+cacheSuffix)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if err := tmp.Chmod(0644); err != nil {
+		return err
+	}
+	zw, err := zstd.NewWriter(tmp)
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(zw).Encode(v); err != nil {
+		zw.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// cachedValue returns the holdings for a given content, either from the
+// on-disk cache in CacheDir or by parsing and indexing the content, which is
+// then written to the cache.
+func cachedValue(p []byte, parse func() (CacheValue, error)) (CacheValue, error) {
+	path := cachePath(CacheDir, p)
+	if v, err := loadCacheValue(path); err == nil {
+		log.Printf("[holdings] cache hit: %s", path)
+		return v, nil
+	} else if !os.IsNotExist(err) {
+		log.Printf("[holdings] ignoring cache file %s: %v", path, err)
+	}
+	v, err := parse()
+	if err != nil {
+		return v, err
+	}
+	if err := saveCacheValue(path, v); err != nil {
+		log.Printf("[holdings] could not write cache: %v", err)
+	}
+	return v, nil
+}
+
+// PruneCacheDir removes cached holdings, that have not been used for the
+// given duration, along with temporary files left behind by aborted runs. A
+// zero duration removes all cached holdings. Temporary files younger than
+// tmpMaxAge are kept, since another process may still write them. It returns
+// the number of files removed.
+func PruneCacheDir(dir string, maxAge time.Duration) (int, error) {
+	// The pattern matches temporary files as well.
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+cacheSuffix))
+	if err != nil {
+		return 0, err
+	}
+	var removed int
+	for _, match := range matches {
+		fi, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		age := time.Since(fi.ModTime())
+		if strings.HasPrefix(filepath.Base(match), tmpPrefix) {
+			if age < tmpMaxAge {
+				continue
+			}
+		} else if maxAge > 0 && age < maxAge {
+			continue
+		}
+		if err := os.Remove(match); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	CacheDir = dir
+	defer func() { CacheDir = "" }()
+	var (
+		header = "publication_title\tprint_identifier\tdate_first_issue_online\n"
+		v1     = header + "A\t1111-1111\t2000\n"
+		v2     = header + "A\t1111-1111\t2001\n"
+		c      = make(HoldingsCache)
+	)
+	count := func() int {
+		matches, err := filepath.Glob(filepath.Join(dir, "*"+cacheSuffix))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(matches)
+	}
+	if err := c.register("a", strings.NewReader(v1)); err != nil {
+		t.Fatal(err)
+	}
+	if count() != 1 {
+		t.Fatalf("got %d cache files, want 1", count())
+	}
+	// Same content under another name is served from cache.
+	if err := c.register("b", strings.NewReader(v1)); err != nil {
+		t.Fatal(err)
+	}
+	if count() != 1 {
+		t.Fatalf("got %d cache files, want 1", count())
+	}
+	if !slices.Equal(c["b"].Entries, c["a"].Entries) || !slices.Equal(c["b"].SerialNumberMap["1111-1111"], []int32{0}) {
+		t.Errorf("cached value differs: %v", c["b"])
+	}
+	// Changed content is parsed again.
+	if err := c.register("c", strings.NewReader(v2)); err != nil {
+		t.Fatal(err)
+	}
+	if count() != 2 {
+		t.Fatalf("got %d cache files, want 2", count())
+	}
+	if got := c["c"].Entries[0].FirstIssueDate; got != "2001" {
+		t.Errorf("got %v, want 2001", got)
+	}
+	// A temporary file of a concurrent writer is kept, a stale one is not.
+	var (
+		fresh = filepath.Join(dir, tmpPrefix+"fresh"+cacheSuffix)
+		stale = filepath.Join(dir, tmpPrefix+"stale"+cacheSuffix)
+		past  = time.Now().Add(-2 * tmpMaxAge)
+	)
+	for _, name := range []string{fresh, stale} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(stale, past, past); err != nil {
+		t.Fatal(err)
+	}
+	n, err := PruneCacheDir(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || count() != 1 {
+		t.Errorf("pruned %d files, %d left", n, count())
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("temporary file removed: %v", err)
+	}
+}
@@ -94,12 +94,18 @@
 //     }
 //
 // That is all. We need to register the filter, so we can use it in the configuration file.
-// Add an entry to filterRegistry in filter.go:
+// Builtin filters are listed in filterRegistry in filter.go, code outside this package
+// can register a filter from an init function:
 //
-//     var filterRegistry = map[string]func() Filter{
-//         ...
-//         "awesome": func() Filter { return &AwesomeFilter{} },
-//         ...
+//     func init() {
+//         filter.Register("awesome", func() filter.Filter { return &AwesomeFilter{} })
+//     }
+//
+// To serialize a tree containing the filter, e.g. one created with the builder
+// functions, the filter needs a MarshalJSON method, that emits the same top level key:
+//
+//     func (f *AwesomeFilter) MarshalJSON() ([]byte, error) {
+//         return json.Marshal(map[string]struct{}{"awesome": {}})
 //     }
 //
 // We can then use the filter in the JSON configuration:
@@ -107,5 +113,10 @@
 //     {"DE-X": {"awesome": {}}}
 //
 //
+// A filter may also implement Explainer to report, why it matched a record or
+// not. The holdings filter uses this to record the KBART entries it considered
+// and the reasons they were rejected. Use Tagger.TagExplain or span-tag
+// -explain to get a trace per label.
+//
 // Further readings: http://theory.stanford.edu/~sergei/papers/sigmod10-index.pdf
 package filter
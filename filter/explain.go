@@ -0,0 +1,203 @@
+package filter
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+)
+
+// Trace records the decision of a single filter node for a record. Logic
+// filters carry the traces of the children they evaluated, holdings filters
+// carry the KBART entries they considered.
+type Trace struct {
+	Filter   string       `json:"filter"`
+	Match    bool         `json:"match"`
+	Reason   string       `json:"reason,omitempty"`
+	Entries  []EntryTrace `json:"entries,omitempty"`
+	Children []Trace      `json:"children,omitempty"`
+}
+
+// EntryTrace describes a KBART entry considered by a holdings filter and, if
+// the entry did not cover the record, the reason, e.g. "before moving wall".
+type EntryTrace struct {
+	Name             string `json:"name"` // filename or URL of holdings document
+	Key              string `json:"key"`  // ZDB-ID, ISSN, ISBN, DOI or title used for the lookup
+	PublicationTitle string `json:"title,omitempty"`
+	FirstIssueDate   string `json:"begin,omitempty"`
+	LastIssueDate    string `json:"end,omitempty"`
+	Embargo          string `json:"embargo,omitempty"`
+	Err              string `json:"err,omitempty"`
+}
+
+// newEntryTrace records an entry lookup and its outcome.
+func newEntryTrace(name, key string, entry licensing.Entry, err error) EntryTrace {
+	et := EntryTrace{
+		Name:             name,
+		Key:              key,
+		PublicationTitle: entry.PublicationTitle,
+		FirstIssueDate:   entry.FirstIssueDate,
+		LastIssueDate:    entry.LastIssueDate,
+		Embargo:          entry.Embargo,
+	}
+	if err != nil {
+		et.Err = err.Error()
+	}
+	return et
+}
+
+// Explainer is implemented by filters that can report, why they matched a
+// record or not. The match value of the returned trace must be the same as
+// the result of Apply.
+type Explainer interface {
+	Explain(finc.IntermediateSchema) Trace
+}
+
+// Explain returns a trace for any filter. Filters that do not implement
+// Explainer get a trace with their name and the result of Apply.
+func Explain(f Filter, is finc.IntermediateSchema) Trace {
+	if e, ok := f.(Explainer); ok {
+		return e.Explain(is)
+	}
+	return Trace{Filter: filterName(f), Match: f.Apply(is)}
+}
+
+// filterName returns the registered name of a filter, or its type name, if
+// the filter is not registered.
+func filterName(f Filter) string {
+	t := reflect.TypeOf(f)
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for name, newFilter := range filterRegistry {
+		if reflect.TypeOf(newFilter()) == t {
+			return name
+		}
+	}
+	return t.String()
+}
+
+// Explain evaluates the children like Apply and keeps their traces.
+func (f *OrFilter) Explain(is finc.IntermediateSchema) Trace {
+	trace := Trace{Filter: "or"}
+	for _, g := range f.Filters {
+		child := Explain(g, is)
+		trace.Children = append(trace.Children, child)
+		if child.Match {
+			trace.Match = true
+			break
+		}
+	}
+	return trace
+}
+
+// Explain evaluates the children like Apply and keeps their traces.
+func (f *AndFilter) Explain(is finc.IntermediateSchema) Trace {
+	trace := Trace{Filter: "and", Match: true}
+	for _, g := range f.Filters {
+		child := Explain(g, is)
+		trace.Children = append(trace.Children, child)
+		if !child.Match {
+			trace.Match = false
+			break
+		}
+	}
+	return trace
+}
+
+// Explain inverts the trace of the wrapped filter.
+func (f *NotFilter) Explain(is finc.IntermediateSchema) Trace {
+	child := Explain(f.Filter, is)
+	return Trace{Filter: "not", Match: !child.Match, Children: []Trace{child}}
+}
+
+// Explain is like Apply, but records every KBART entry that was considered,
+// together with the reason it did not cover the record.
+func (f *HoldingsFilter) Explain(is finc.IntermediateSchema) Trace {
+	trace := Trace{Filter: "holdings"}
+	var (
+		zdb   = licensing.NormalizeZDBID(is.ZDBID)
+		issns = slices.Concat(is.ISSN, is.EISSN)
+	)
+	for _, key := range f.Names {
+		item := Cache[key]
+		index, keys := serialKeys(item, zdb, issns)
+		for _, k := range keys {
+			for _, i := range index[k] {
+				entry := item.Entries[i]
+				err := entry.Covers(is.RawDate, is.Volume, is.Issue)
+				trace.Entries = append(trace.Entries, newEntryTrace(key, k, entry, err))
+				if err == nil {
+					trace.Match = true
+					return trace
+				}
+			}
+		}
+	}
+	isbns, dois := monographKeys(is)
+	for _, key := range f.Names {
+		item := Cache[key]
+		for _, isbn := range isbns {
+			for _, i := range item.ISBNMap[isbn] {
+				entry := item.Entries[i]
+				err := entry.Covers(is.RawDate, is.Volume, is.Issue)
+				trace.Entries = append(trace.Entries, newEntryTrace(key, isbn, entry, err))
+				if err == nil {
+					trace.Match = true
+					return trace
+				}
+			}
+		}
+		for _, doi := range dois {
+			for _, i := range item.DOIPrefixMap[doi] {
+				entry := item.Entries[i]
+				err := entry.Covers(is.RawDate, is.Volume, is.Issue)
+				trace.Entries = append(trace.Entries, newEntryTrace(key, doi, entry, err))
+				if err == nil {
+					trace.Match = true
+					return trace
+				}
+			}
+		}
+	}
+	if f.CompareByTitle {
+		title := licensing.NormalizeTitle(is.JournalTitle)
+		for _, key := range f.Names {
+			item := Cache[key]
+			for _, m := range f.titleMatches(key, title) {
+				for _, i := range item.TitleMap[m.Title] {
+					entry := item.Entries[i]
+					err := entry.Covers(is.RawDate, is.Volume, is.Issue)
+					trace.Entries = append(trace.Entries, newEntryTrace(key, m.Title, entry, err))
+					if err == nil {
+						trace.Match = true
+						return trace
+					}
+				}
+			}
+		}
+	}
+	if len(trace.Entries) == 0 {
+		trace.Reason = "no holdings entry for record"
+	}
+	return trace
+}
+
+// Explain returns the trace of the root filter.
+func (t *Tree) Explain(is finc.IntermediateSchema) Trace {
+	return Explain(t.Root, is)
+}
+
+// TagExplain is like Tag, but additionally returns the decision trace for
+// each label in the filter map, whether it was attached or not.
+func (t *Tagger) TagExplain(is finc.IntermediateSchema) (finc.IntermediateSchema, map[string]Trace) {
+	traces := make(map[string]Trace, len(t.FilterMap))
+	for tag, filter := range t.FilterMap {
+		trace := filter.Explain(is)
+		if trace.Match {
+			is.Labels = append(is.Labels, tag)
+		}
+		traces[tag] = trace
+	}
+	return is, traces
+}
@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestExplainLogic(t *testing.T) {
+	s := `
+    {
+        "or":[
+            {"and": [{"source":["1"]}, {"collection":["A"]}]},
+            {"not": {"source":["2"]}}
+        ]
+    }
+    `
+	var tree Tree
+	if err := json.Unmarshal([]byte(s), &tree); err != nil {
+		t.Fatalf("invalid filter: %s", err)
+	}
+	var tests = []struct {
+		about  string
+		record finc.IntermediateSchema
+		result bool
+		paths  int // number of children evaluated by the root
+	}{
+		{"first branch", finc.IntermediateSchema{SourceID: "1", MegaCollections: []string{"A"}}, true, 1},
+		{"second branch", finc.IntermediateSchema{SourceID: "3"}, true, 2},
+		{"no match", finc.IntermediateSchema{SourceID: "2"}, false, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			trace := tree.Explain(test.record)
+			if trace.Match != tree.Apply(test.record) {
+				t.Fatalf("Explain and Apply disagree: %v", trace.Match)
+			}
+			if trace.Match != test.result {
+				t.Errorf("Explain got %v, want %v", trace.Match, test.result)
+			}
+			if trace.Filter != "or" {
+				t.Errorf("got filter %q, want or", trace.Filter)
+			}
+			if len(trace.Children) != test.paths {
+				t.Errorf("got %d children, want %d", len(trace.Children), test.paths)
+			}
+		})
+	}
+}
+
+func TestExplainHoldings(t *testing.T) {
+	Cache["explain-test"] = NewCacheValue([]licensing.Entry{
+		{PublicationTitle: "Journal", PrintIdentifier: "1234-5678", FirstIssueDate: "2000", LastIssueDate: "2005"},
+	})
+	defer delete(Cache, "explain-test")
+	f := &HoldingsFilter{Names: []string{"explain-test"}}
+	var tests = []struct {
+		about  string
+		record finc.IntermediateSchema
+		result bool
+		err    string
+		reason string
+	}{
+		{"covered", finc.IntermediateSchema{ISSN: []string{"1234-5678"}, RawDate: "2001"}, true, "", ""},
+		{"too late", finc.IntermediateSchema{ISSN: []string{"1234-5678"}, RawDate: "2010"}, false,
+			licensing.ErrAfterLastIssueDate.Error(), ""},
+		{"unknown issn", finc.IntermediateSchema{ISSN: []string{"0000-0000"}, RawDate: "2001"}, false,
+			"", "no holdings entry for record"},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			trace := f.Explain(test.record)
+			if trace.Match != test.result {
+				t.Errorf("Explain got %v, want %v", trace.Match, test.result)
+			}
+			if trace.Reason != test.reason {
+				t.Errorf("got reason %q, want %q", trace.Reason, test.reason)
+			}
+			if test.err != "" {
+				if len(trace.Entries) != 1 || trace.Entries[0].Err != test.err {
+					t.Errorf("got entries %v, want single entry with %q", trace.Entries, test.err)
+				}
+			}
+		})
+	}
+}
+
+func TestTagExplain(t *testing.T) {
+	tagger := NewTagger().
+		Add("DE-1", Source("1")).
+		Add("DE-2", Source("2"))
+	is, traces := tagger.TagExplain(finc.IntermediateSchema{SourceID: "1"})
+	if len(is.Labels) != 1 || is.Labels[0] != "DE-1" {
+		t.Errorf("got labels %v, want [DE-1]", is.Labels)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("got %d traces, want 2", len(traces))
+	}
+	if !traces["DE-1"].Match || traces["DE-2"].Match {
+		t.Errorf("unexpected traces: %v", traces)
+	}
+	if traces["DE-2"].Filter != "source" {
+		t.Errorf("got filter %q, want source", traces["DE-2"].Filter)
+	}
+}
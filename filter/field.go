@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats/finc"
+)
+
+// schemaFields maps JSON keys of the intermediate schema to the index of the
+// corresponding string or string slice struct field.
+var schemaFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(finc.IntermediateSchema{})
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		switch {
+		case sf.Type.Kind() == reflect.String:
+		case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String:
+		default:
+			continue
+		}
+		fields[name] = i
+	}
+	return fields
+}()
+
+// FieldFilter allows records, where a field of the intermediate schema
+// matches any one of the given values exactly, by prefix or by regular
+// expression. Fields are named by their JSON key, e.g. "rft.jtitle" or
+// "x.subjects"; only string and string list fields are supported.
+//
+//	{"field": {"name": "rft.jtitle", "prefix": ["Journal of"]}}
+type FieldFilter struct {
+	Name   string
+	Exact  []string
+	Prefix []string
+	Regex  []string
+
+	index    int
+	exact    *container.StringSet
+	patterns []*regexp.Regexp
+}
+
+// compile resolves the field name and compiles the patterns.
+func (f *FieldFilter) compile() error {
+	index, ok := schemaFields[f.Name]
+	if !ok {
+		return fmt.Errorf("field: unknown or unsupported field: %q", f.Name)
+	}
+	f.index = index
+	f.exact = container.NewStringSet(f.Exact...)
+	f.patterns = nil
+	for _, expr := range f.Regex {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("field: %w", err)
+		}
+		f.patterns = append(f.patterns, re)
+	}
+	return nil
+}
+
+// values returns the values of the configured field.
+func (f *FieldFilter) values(is finc.IntermediateSchema) []string {
+	v := reflect.ValueOf(is).Field(f.index)
+	if v.Kind() == reflect.String {
+		return []string{v.String()}
+	}
+	return v.Interface().([]string)
+}
+
+// match returns true, if a single value matches any of the configured values.
+func (f *FieldFilter) match(s string) bool {
+	if f.exact.Contains(s) {
+		return true
+	}
+	for _, prefix := range f.Prefix {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	for _, re := range f.patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply filter.
+func (f *FieldFilter) Apply(is finc.IntermediateSchema) bool {
+	for _, s := range f.values(is) {
+		if s != "" && f.match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON turns a config fragment into a filter.
+func (f *FieldFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Field struct {
+			Name   string   `json:"name"`
+			Exact  []string `json:"exact"`
+			Prefix []string `json:"prefix"`
+			Regex  []string `json:"regex"`
+		} `json:"field"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.Name = s.Field.Name
+	f.Exact = s.Field.Exact
+	f.Prefix = s.Field.Prefix
+	f.Regex = s.Field.Regex
+	return f.compile()
+}
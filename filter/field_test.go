@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestFieldFilter(t *testing.T) {
+	var tests = []struct {
+		about  string
+		config string
+		record finc.IntermediateSchema
+		result bool
+	}{
+		{"exact string", `{"field": {"name": "rft.jtitle", "exact": ["Nature"]}}`,
+			finc.IntermediateSchema{JournalTitle: "Nature"}, true},
+		{"exact string, no match", `{"field": {"name": "rft.jtitle", "exact": ["Nature"]}}`,
+			finc.IntermediateSchema{JournalTitle: "Nature Physics"}, false},
+		{"prefix", `{"field": {"name": "rft.jtitle", "prefix": ["Nature"]}}`,
+			finc.IntermediateSchema{JournalTitle: "Nature Physics"}, true},
+		{"regex", `{"field": {"name": "rft.atitle", "regex": ["(?i)\\bawesome\\b"]}}`,
+			finc.IntermediateSchema{ArticleTitle: "An Awesome Title"}, true},
+		{"regex, no match", `{"field": {"name": "rft.atitle", "regex": ["(?i)\\bawesome\\b"]}}`,
+			finc.IntermediateSchema{ArticleTitle: "Awesomeness"}, false},
+		{"list field", `{"field": {"name": "x.subjects", "exact": ["Physics"]}}`,
+			finc.IntermediateSchema{Subjects: []string{"Chemistry", "Physics"}}, true},
+		{"combined modes", `{"field": {"name": "rft.pub", "exact": ["A"], "prefix": ["Spr"]}}`,
+			finc.IntermediateSchema{Publishers: []string{"Springer"}}, true},
+		{"empty field", `{"field": {"name": "rft.jtitle", "regex": [".*"]}}`,
+			finc.IntermediateSchema{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			var tree Tree
+			if err := json.Unmarshal([]byte(test.config), &tree); err != nil {
+				t.Fatalf("invalid filter: %s", err)
+			}
+			if result := tree.Apply(test.record); result != test.result {
+				t.Errorf("Apply got %v, want %v", result, test.result)
+			}
+		})
+	}
+}
+
+func TestFieldFilterInvalid(t *testing.T) {
+	for _, s := range []string{
+		`{"field": {"name": "no.such.field", "exact": ["x"]}}`,
+		`{"field": {"name": "authors", "exact": ["x"]}}`,
+		`{"field": {"name": "rft.jtitle", "regex": ["("]}}`,
+	} {
+		var tree Tree
+		if err := json.Unmarshal([]byte(s), &tree); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
@@ -15,9 +15,15 @@
 package filter
 
 import (
+	"archive/zip"
 	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sync"
 
 	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/freeze"
 	"github.com/segmentio/encoding/json"
 )
 
@@ -90,17 +96,46 @@ func (t *Tagger) Expand(rules map[string][]string) {
 	}
 }
 
+// ReadTagger reads a filterconfig from a JSON file or from a frozen zip file,
+// as created by span-freeze. Holdings files referenced by a frozen config are
+// loaded from the zip file.
+func ReadTagger(filename string) (*Tagger, error) {
+	if zr, err := zip.OpenReader(filename); err == nil {
+		zr.Close()
+		dir, blob, err := freeze.UnfreezeFilterConfig(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		filename = blob
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var tagger Tagger
+	if err := json.Unmarshal(b, &tagger); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &tagger, nil
+}
+
 // filterRegistry maps filter names to factory functions that return a new
-// zero-value instance of the filter. To register a new filter, add an entry
-// here.
+// zero-value instance of the filter. Builtin filters are listed here, other
+// filters can be added with Register.
 var filterRegistry = map[string]func() Filter{
 	"any":        func() Filter { return &AnyFilter{} },
 	"and":        func() Filter { return &AndFilter{} },
 	"collection": func() Filter { return &CollectionFilter{} },
+	"date":       func() Filter { return &DateFilter{} },
 	"doi":        func() Filter { return &DOIFilter{} },
+	"field":      func() Filter { return &FieldFilter{} },
+	"format":     func() Filter { return &FormatFilter{} },
 	"holdings":   func() Filter { return &HoldingsFilter{} },
 	"isbn":       func() Filter { return &ISBNFilter{} },
 	"issn":       func() Filter { return &ISSNFilter{} },
+	"language":   func() Filter { return &LanguageFilter{} },
+	"license":    func() Filter { return &LicenseFilter{} },
 	"not":        func() Filter { return &NotFilter{} },
 	"or":         func() Filter { return &OrFilter{} },
 	"package":    func() Filter { return &PackageFilter{} },
@@ -108,11 +143,51 @@ var filterRegistry = map[string]func() Filter{
 	"subject":    func() Filter { return &SubjectFilter{} },
 }
 
+// registryMu guards filterRegistry.
+var registryMu sync.RWMutex
+
+// Register makes a filter available under the given name in a filter
+// configuration. The factory must return a new zero-value instance of the
+// filter, which will then be populated via UnmarshalJSON from a fragment like
+// {"name": ...}. For configurations to round-trip, the filter should
+// implement MarshalJSON and emit the same single top level key. Register
+// panics, if it is called twice with the same name or if the factory is nil;
+// it is meant to be called from init functions.
+func Register(name string, newFilter func() Filter) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if name == "" {
+		panic("filter: Register name is empty")
+	}
+	if newFilter == nil {
+		panic("filter: Register factory is nil")
+	}
+	if _, dup := filterRegistry[name]; dup {
+		panic("filter: Register called twice for filter " + name)
+	}
+	filterRegistry[name] = newFilter
+}
+
+// Registered returns a sorted list of the names of all registered filters.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return slices.Sorted(maps.Keys(filterRegistry))
+}
+
+// lookupFilter returns the factory for a filter name.
+func lookupFilter(name string) (func() Filter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	newFilter, ok := filterRegistry[name]
+	return newFilter, ok
+}
+
 // unmarshalFilter takes the name of a filter and a raw JSON message and
 // unmarshals the appropriate filter. All filters must be registered in
 // filterRegistry. Unknown filters cause an error.
 func unmarshalFilter(name string, raw json.RawMessage) (Filter, error) {
-	newFilter, ok := filterRegistry[name]
+	newFilter, ok := lookupFilter(name)
 	if !ok {
 		return nil, fmt.Errorf("unknown filter: %s", name)
 	}
@@ -1,6 +1,10 @@
 package filter
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/miku/span/formats/finc"
@@ -160,6 +164,26 @@ func TestTaggerExpandMissing(t *testing.T) {
 	}
 }
 
+func TestReadTagger(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "filterconfig.json")
+	if err := os.WriteFile(filename, []byte(`{"DE-15": {"source": ["49"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tagger, err := ReadTagger(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tagger.FilterMap["DE-15"]; !ok || len(tagger.FilterMap) != 1 {
+		t.Errorf("got %v, want DE-15", tagger.FilterMap)
+	}
+	if err := os.WriteFile(filename, []byte(`{"DE-15": {"unknown": {}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTagger(filename); err == nil || !strings.HasPrefix(err.Error(), filename) {
+		t.Errorf("got %v, want error with filename", err)
+	}
+}
+
 func TestNotFilter1(t *testing.T) {
 	s := `
     {
@@ -195,3 +219,71 @@ func TestNotFilter1(t *testing.T) {
 		})
 	}
 }
+
+// titleFilter is a custom filter used to test registration.
+type titleFilter struct {
+	Word string
+}
+
+func (f *titleFilter) Apply(is finc.IntermediateSchema) bool {
+	return strings.Contains(is.ArticleTitle, f.Word)
+}
+
+func (f *titleFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Word string `json:"test-title"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.Word = s.Word
+	return nil
+}
+
+func (f *titleFilter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"test-title": f.Word})
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-title", func() Filter { return &titleFilter{} })
+	defer func() {
+		registryMu.Lock()
+		delete(filterRegistry, "test-title")
+		registryMu.Unlock()
+	}()
+	if !slices.Contains(Registered(), "test-title") {
+		t.Fatalf("test-title not in registered filters: %v", Registered())
+	}
+	var tagger Tagger
+	if err := json.Unmarshal([]byte(`{"DE-X": {"and": [{"source": ["1"]}, {"test-title": "awesome"}]}}`), &tagger); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	is := tagger.Tag(finc.IntermediateSchema{SourceID: "1", ArticleTitle: "An awesome title"})
+	if len(is.Labels) != 1 {
+		t.Errorf("got labels %v, want [DE-X]", is.Labels)
+	}
+	// Round trip.
+	b, err := json.Marshal(&tagger)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got Tagger
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal round trip: %v", err)
+	}
+	is = got.Tag(finc.IntermediateSchema{SourceID: "1", ArticleTitle: "A boring title"})
+	if len(is.Labels) != 0 {
+		t.Errorf("got labels %v, want none", is.Labels)
+	}
+	// Duplicates are not allowed.
+	for _, name := range []string{"test-title", "holdings"} {
+		t.Run("duplicate "+name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("Register(%q) twice did not panic", name)
+				}
+			}()
+			Register(name, func() Filter { return &titleFilter{} })
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package filter
+
+import (
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats/finc"
+)
+
+// FormatFilter allows records with any one of the given formats, e.g.
+// "eJournal" or "ElectronicArticle".
+type FormatFilter struct {
+	Values *container.StringSet
+}
+
+// Apply filter.
+func (f *FormatFilter) Apply(is finc.IntermediateSchema) bool {
+	return f.Values.Contains(is.Format)
+}
+
+// UnmarshalJSON turns a config fragment into a filter.
+func (f *FormatFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Formats []string `json:"format"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.Values = container.NewStringSet(s.Formats...)
+	return nil
+}
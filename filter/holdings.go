@@ -2,8 +2,11 @@ package filter
 
 import (
 	"archive/zip"
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/segmentio/encoding/json"
@@ -16,18 +19,40 @@ import (
 	"github.com/miku/span/xio"
 )
 
-// CacheValue groups holdings and cache for fast lookups.
+// CacheValue groups holdings and indices for fast lookups. Each entry of a
+// holdings file is stored once, the maps refer to entries by offset.
 type CacheValue struct {
-	SerialNumberMap map[string][]licensing.Entry `json:"s"` // key: ISSN
-	WisoDatabaseMap map[string][]licensing.Entry `json:"w"` // key: WISO DB name
-	TitleMap        map[string][]licensing.Entry `json:"t"` // key: publication title
+	Entries         []licensing.Entry `json:"e"`
+	SerialNumberMap kbart.Index       `json:"s"` // key: ISSN
+	ZDBMap          kbart.Index       `json:"z"` // key: ZDB-ID
+	WisoDatabaseMap kbart.Index       `json:"w"` // key: WISO DB name
+	TitleMap        kbart.Index       `json:"t"` // key: publication title
+	ISBNMap         kbart.Index       `json:"i"` // key: ISBN-13 of ebook entries
+	DOIPrefixMap    kbart.Index       `json:"d"` // key: DOI of ebook entries
+}
+
+// NewCacheValue removes duplicate entries, interns strings and builds the
+// lookup indices. The given slice is modified in place.
+func NewCacheValue(entries []licensing.Entry) CacheValue {
+	h := kbart.Holdings(entries)
+	h.Unique()
+	h.Intern()
+	return CacheValue{
+		Entries:         h,
+		SerialNumberMap: h.SerialNumberIndex(),
+		ZDBMap:          h.ZDBIndex(),
+		WisoDatabaseMap: h.WisoDatabaseIndex(),
+		TitleMap:        h.TitleIndex(),
+		ISBNMap:         h.ISBNIndex(),
+		DOIPrefixMap:    h.DOIPrefixIndex(),
+	}
 }
 
 // HoldingsCache caches items keyed by filename or url. A configuration might
 // refer to the same holding file hundreds or thousands of times, but we only
 // want to store the content once. This map serves as a private singleton that
 // holds licensing entries and precomputed shortcuts to find relevant entries
-// (rows from KBART) by ISSN, wiso database name or title.
+// (rows from KBART) by ISSN, ZDB-ID, ISBN, DOI, wiso database name or title.
 type HoldingsCache map[string]CacheValue
 
 // register reads a holding file from a reader and caches it under the given
@@ -37,42 +62,111 @@ func (c *HoldingsCache) register(key string, r io.Reader) error {
 		log.Printf("[holdings] already cached: %s", key)
 		return nil
 	}
-	h := new(kbart.Holdings)
-	if _, err := h.ReadFrom(r); err != nil {
+	v, err := readCacheValue(r)
+	if err != nil {
 		return err
 	}
-	// Precompute shortcuts to entries.
-	(*c)[key] = CacheValue{
-		SerialNumberMap: h.SerialNumberMap(),
-		WisoDatabaseMap: h.WisoDatabaseMap(),
-		TitleMap:        h.TitleMap(),
-	}
 	if rc, ok := r.(io.Closer); ok {
-		return rc.Close()
+		if err := rc.Close(); err != nil {
+			return err
+		}
 	}
+	(*c)[key] = v
 	return nil
 }
 
-// putFile parses a holding file and adds it to the cache.
-func (c *HoldingsCache) putFile(filename string) error {
-	_, err := zip.OpenReader(filename)
+// readCacheValue parses and indexes holdings. Files are only read into memory
+// as a whole, if the on-disk cache is enabled, since the cache is keyed by
+// content.
+func readCacheValue(r io.Reader) (CacheValue, error) {
+	parse := func(r io.Reader) (CacheValue, error) {
+		h := new(kbart.Holdings)
+		if _, err := h.ReadFrom(r); err != nil {
+			return CacheValue{}, err
+		}
+		// Precompute shortcuts to entries.
+		return NewCacheValue(*h), nil
+	}
+	if CacheDir == "" {
+		return parse(r)
+	}
+	p, err := io.ReadAll(r)
 	if err != nil {
-		file, err := os.Open(filename)
-		if err != nil {
-			return err
+		return CacheValue{}, err
+	}
+	return cachedValue(p, func() (CacheValue, error) {
+		return parse(bytes.NewReader(p))
+	})
+}
+
+// openHoldings opens a holdings file. Zip archives are read as the
+// concatenation of their members, except for XLSX and ODS spreadsheets, which
+// are zip archives themselves and are handled by the KBART reader. The file
+// is not required to be seekable, so process substitution works.
+func openHoldings(filename string) (io.Reader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := make([]byte, 4096)
+	n, err := io.ReadFull(f, p)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		f.Close()
+		return nil, err
+	}
+	r := struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(p[:n]), f), f}
+	format, _ := kbart.Sniff(p[:n])
+	switch {
+	case format == kbart.FormatXLSX || format == kbart.FormatODS:
+		log.Printf("[holdings] read (%s): %s", format, filename)
+		return r, nil
+	case bytes.HasPrefix(p[:n], []byte("PK\x03\x04")):
+		if zr, err := zip.OpenReader(filename); err == nil {
+			zr.Close()
+			f.Close()
+			log.Printf("[holdings] read (zip): %s", filename)
+			return &xio.ZipContentReader{Filename: filename}, nil
 		}
-		log.Printf("[holdings] read: %s", filename)
-		return c.register(filename, file)
 	}
-	r := &xio.ZipContentReader{Filename: filename}
-	log.Printf("[holdings] read (zip): %s", filename)
+	log.Printf("[holdings] read: %s", filename)
+	return r, nil
+}
+
+// putFile parses a holding file and adds it to the cache. The format of the
+// file is detected, refs. kbart.Sniff.
+func (c *HoldingsCache) putFile(filename string) error {
+	if _, ok := (*c)[filename]; ok {
+		log.Printf("[holdings] already cached: %s", filename)
+		return nil
+	}
+	r, err := openHoldings(filename)
+	if err != nil {
+		return err
+	}
 	return c.register(filename, r)
 }
 
 // putLink parses a holding file from a link and adds it to the cache.
 func (c *HoldingsCache) putLink(link string) error {
+	if _, ok := (*c)[link]; ok {
+		log.Printf("[holdings] already cached: %s", link)
+		return nil
+	}
 	log.Printf("[holdings] fetch: %s", link)
-	return c.register(link, &xio.ZipOrPlainLinkReader{Link: link})
+	slink := xio.SavedLink{Link: link}
+	filename, err := slink.Save()
+	if err != nil {
+		return err
+	}
+	defer slink.Remove()
+	r, err := openHoldings(filename)
+	if err != nil {
+		return err
+	}
+	return c.register(link, r)
 }
 
 // Cache caches holdings information.
@@ -87,8 +181,11 @@ type HoldingsFilter struct {
 	// Keep cache keys only (filename or URL of holdings document).
 	Names   []string `json:"-"`
 	Verbose bool     `json:"verbose,omitempty"`
-	// Beside ISSN, also try to compare by title, this is fuzzy, so disabled by default.
+	// Beside ISSN, also try to compare by journal title, this is fuzzy, so disabled by default.
 	CompareByTitle bool `json:"compare-by-title,omitempty"`
+	// If between 0 and 1, also accept titles with at least this trigram
+	// similarity, otherwise normalized titles must be equal.
+	TitleThreshold float64 `json:"title-threshold,omitempty"`
 	// Allow direct access to entries, might replace Names.
 	CachedValues map[string]*CacheValue `json:"cache,omitempty"`
 }
@@ -110,11 +207,15 @@ func (f *HoldingsFilter) UnmarshalJSON(p []byte) error {
 			Links          []string `json:"urls"`
 			Verbose        bool     `json:"verbose"`
 			CompareByTitle bool     `json:"compare-by-title"`
+			TitleThreshold float64  `json:"title-threshold"`
 		} `json:"holdings"`
 	}
 	if err := json.Unmarshal(p, &s); err != nil {
 		return err
 	}
+	if t := s.Holdings.TitleThreshold; t < 0 || t > 1 {
+		return fmt.Errorf("holdings: title-threshold must be between 0 and 1, got %v", t)
+	}
 	for _, fn := range s.Holdings.Filenames {
 		// TODO: we may want to support file:///a/b.txt and /a/b.txt here, too.
 		if strings.HasPrefix(fn, "file://") {
@@ -148,17 +249,63 @@ func (f *HoldingsFilter) UnmarshalJSON(p []byte) error {
 	}
 	f.Verbose = s.Holdings.Verbose
 	f.CompareByTitle = s.Holdings.CompareByTitle
+	f.TitleThreshold = s.Holdings.TitleThreshold
 	if f.CachedValues == nil {
 		f.CachedValues = make(map[string]*CacheValue)
 	}
 	for _, name := range f.Names {
 		item := Cache[name]
 		f.CachedValues[name] = &item
+		if f.CompareByTitle && f.TitleThreshold > 0 && f.TitleThreshold < 1 {
+			titleIndexFor(name)
+		}
 	}
 	log.Printf("[holdings] loaded %d files or links with %d entries", len(f.Names), f.count())
 	return nil
 }
 
+// doiPrefixes returns a DOI and all its prefixes, that end before a "_", "-",
+// "." or "/" in the suffix, longest first. A chapter DOI like
+// 10.1007/978-3-658-10838-0_5 yields the book DOI 10.1007/978-3-658-10838-0
+// among others.
+func doiPrefixes(doi string) []string {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	k := strings.Index(doi, "/")
+	if !strings.HasPrefix(doi, "10.") || k < 0 || k == len(doi)-1 {
+		return nil
+	}
+	prefixes := []string{doi}
+	for i := len(doi) - 1; i > k+1; i-- {
+		if strings.ContainsRune("_-./", rune(doi[i])) {
+			prefixes = append(prefixes, doi[:i])
+		}
+	}
+	return prefixes
+}
+
+// monographKeys returns the normalized ISBN and the DOI prefixes of a record,
+// which are used to find ebook entries.
+func monographKeys(is finc.IntermediateSchema) (isbns, dois []string) {
+	for _, v := range is.ISBNList() {
+		if s := licensing.NormalizeISBN(v); s != "" && !slices.Contains(isbns, s) {
+			isbns = append(isbns, s)
+		}
+	}
+	return isbns, doiPrefixes(is.DOI)
+}
+
+// serialKeys returns the index and keys to look up a serial in a single
+// holdings file. A ZDB-ID identifies a journal more precisely than an ISSN,
+// which may be shared by predecessor and successor titles. If the file has
+// entries for the ZDB-ID, they alone decide, otherwise ISSN are used, since
+// many files, e.g. from publishers, have no zdb_id column.
+func serialKeys(item CacheValue, zdb string, issns []string) (kbart.Index, []string) {
+	if zdb != "" && len(item.ZDBMap[zdb]) > 0 {
+		return item.ZDBMap, []string{zdb}
+	}
+	return item.SerialNumberMap, issns
+}
+
 // covers returns true, if entry covers given document.
 func (f *HoldingsFilter) covers(entry licensing.Entry, is finc.IntermediateSchema) bool {
 	err := entry.Covers(is.RawDate, is.Volume, is.Issue)
@@ -179,27 +326,93 @@ func (f *HoldingsFilter) covers(entry licensing.Entry, is finc.IntermediateSchem
 // function is very specific: it works only with intermediate format and it uses specific
 // information from that format to decide on attachment.
 func (f *HoldingsFilter) Apply(is finc.IntermediateSchema) bool {
-	// By default test serial number.
-	for _, issn := range append(is.ISSN, is.EISSN...) {
-		for _, key := range f.Names {
-			item := Cache[key]
-			for _, entry := range item.SerialNumberMap[issn] {
-				if f.covers(entry, is) {
+	// Serials are looked up by ZDB-ID or ISSN, per holdings file.
+	var (
+		zdb   = licensing.NormalizeZDBID(is.ZDBID)
+		issns = slices.Concat(is.ISSN, is.EISSN)
+	)
+	for _, key := range f.Names {
+		item := Cache[key]
+		index, keys := serialKeys(item, zdb, issns)
+		for _, k := range keys {
+			for _, i := range index[k] {
+				if f.covers(item.Entries[i], is) {
+					return true
+				}
+			}
+		}
+	}
+	// Books and chapters are looked up in ebook entries by ISBN and DOI.
+	isbns, dois := monographKeys(is)
+	for _, key := range f.Names {
+		item := Cache[key]
+		for _, isbn := range isbns {
+			for _, i := range item.ISBNMap[isbn] {
+				if f.covers(item.Entries[i], is) {
+					return true
+				}
+			}
+		}
+		for _, doi := range dois {
+			for _, i := range item.DOIPrefixMap[doi] {
+				if f.covers(item.Entries[i], is) {
 					return true
 				}
 			}
 		}
 	}
-	// Optionally test by title, refs. #10707.
+	// Optionally test by journal title, refs. #10707.
 	if f.CompareByTitle {
+		title := licensing.NormalizeTitle(is.JournalTitle)
 		for _, key := range f.Names {
 			item := Cache[key]
-			for _, entry := range item.TitleMap[is.ArticleTitle] {
-				if f.covers(entry, is) {
-					return true
+			for _, m := range f.titleMatches(key, title) {
+				for _, i := range item.TitleMap[m.Title] {
+					ok := f.covers(item.Entries[i], is)
+					f.reportTitleMatch(key, is, item.Entries[i], m, ok)
+					if ok {
+						return true
+					}
 				}
 			}
 		}
 	}
 	return false
 }
+
+// titleMatches returns the keys of the title index of a holdings file, that
+// match a normalized title. Equal titles have a score of one, similar titles
+// are only considered, if a threshold is set.
+func (f *HoldingsFilter) titleMatches(name, title string) []titleMatch {
+	if title == "" {
+		return nil
+	}
+	if _, ok := Cache[name].TitleMap[title]; ok {
+		return []titleMatch{{Title: title, Score: 1}}
+	}
+	if f.TitleThreshold <= 0 || f.TitleThreshold >= 1 {
+		return nil
+	}
+	return titleIndexFor(name).similar(title, f.TitleThreshold)
+}
+
+// reportTitleMatch logs a match of a journal title to a differently spelled
+// publication title in verbose mode, so matches can be audited.
+func (f *HoldingsFilter) reportTitleMatch(name string, is finc.IntermediateSchema, entry licensing.Entry, m titleMatch, covers bool) {
+	if !f.Verbose || is.JournalTitle == entry.PublicationTitle {
+		return
+	}
+	msg := map[string]any{
+		"title-match": map[string]any{
+			"id":       is.ID,
+			"title":    is.JournalTitle,
+			"match":    entry.PublicationTitle,
+			"score":    m.Score,
+			"covers":   covers,
+			"holdings": name,
+		},
+	}
+	if b, err := json.Marshal(msg); err == nil {
+		log.Println(string(b))
+	}
+}
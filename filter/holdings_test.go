@@ -0,0 +1,202 @@
+package filter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+	"github.com/miku/span/licensing/kbart"
+)
+
+func TestHoldingsFilter(t *testing.T) {
+	var (
+		a = licensing.Entry{PublicationTitle: "A", PrintIdentifier: "1111-1111", FirstIssueDate: "2000"}
+		b = licensing.Entry{PublicationTitle: "B", OnlineIdentifier: "2222-2222", LastIssueDate: "2000"}
+		c = licensing.Entry{PublicationTitle: "C", OnlineIdentifier: "978-3-658-10838-0",
+			TitleID: "10.1007/978-3-658-10838-0", CoverageDepth: "ebook"}
+		d = licensing.Entry{PublicationTitle: "D", OnlineIdentifier: "9783662479841", CoverageDepth: "Volltext"}
+		// Predecessor and successor title share an ISSN, but not the ZDB-ID.
+		e = licensing.Entry{PublicationTitle: "E", PrintIdentifier: "3333-3333", ZDBID: "1459367-1", FirstIssueDate: "2010"}
+		g = licensing.Entry{PublicationTitle: "G", PrintIdentifier: "3333-3333", ZDBID: "1483471-6", LastIssueDate: "2009"}
+	)
+	// Duplicate entries are removed, so each entry is indexed once.
+	Cache["holdings-test"] = NewCacheValue([]licensing.Entry{a, b, a, c, d, e, g})
+	defer delete(Cache, "holdings-test")
+	if got := len(Cache["holdings-test"].Entries); got != 6 {
+		t.Errorf("got %d entries, want 6", got)
+	}
+	if got := Cache["holdings-test"].SerialNumberMap["1111-1111"]; len(got) != 1 {
+		t.Errorf("got %v, want a single offset", got)
+	}
+	f := &HoldingsFilter{Names: []string{"holdings-test"}, CompareByTitle: true}
+	var tests = []struct {
+		is   finc.IntermediateSchema
+		want bool
+	}{
+		{finc.IntermediateSchema{ISSN: []string{"1111-1111"}, RawDate: "2001"}, true},
+		{finc.IntermediateSchema{ISSN: []string{"1111-1111"}, RawDate: "1999"}, false},
+		{finc.IntermediateSchema{EISSN: []string{"2222-2222"}, RawDate: "1999"}, true},
+		{finc.IntermediateSchema{JournalTitle: "B", RawDate: "1999"}, true},
+		{finc.IntermediateSchema{JournalTitle: "X", RawDate: "1999"}, false},
+		{finc.IntermediateSchema{ArticleTitle: "B", RawDate: "1999"}, false},
+		{finc.IntermediateSchema{ISBN: []string{"3-658-10838-X"}, RawDate: "2016"}, true},
+		{finc.IntermediateSchema{EISBN: []string{"9783658108380"}, RawDate: "2016"}, true},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10838-0_5", RawDate: "2016"}, true},
+		{finc.IntermediateSchema{DOI: "10.1007/978-3-658-10838", RawDate: "2016"}, false},
+		{finc.IntermediateSchema{ISBN: []string{"978-3-662-47984-1"}, RawDate: "2016"}, false},
+		{finc.IntermediateSchema{ISSN: []string{"3333-3333"}, RawDate: "2000"}, true},
+		{finc.IntermediateSchema{ISSN: []string{"3333-3333"}, ZDBID: "1459367-1", RawDate: "2000"}, false},
+		{finc.IntermediateSchema{ISSN: []string{"3333-3333"}, ZDBID: "1459367-1", RawDate: "2011"}, true},
+		{finc.IntermediateSchema{ZDBID: "(DE-600)1483471-6", RawDate: "2000"}, true},
+		{finc.IntermediateSchema{ISSN: []string{"3333-3333"}, ZDBID: "2736054-4", RawDate: "2000"}, true},
+	}
+	for i, test := range tests {
+		if got := f.Apply(test.is); got != test.want {
+			t.Errorf("[%d] got %v, want %v", i, got, test.want)
+		}
+		if got := f.Explain(test.is).Match; got != test.want {
+			t.Errorf("[%d] Explain got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestHoldingsFilterZDBPerFile(t *testing.T) {
+	var (
+		// A file with zdb_id column, which does not cover the record, and a
+		// publisher file without, which covers it by ISSN.
+		a = licensing.Entry{PublicationTitle: "A", PrintIdentifier: "4444-4444", ZDBID: "2736054-4", LastIssueDate: "2005"}
+		b = licensing.Entry{PublicationTitle: "A", PrintIdentifier: "4444-4444", FirstIssueDate: "2010"}
+	)
+	Cache["holdings-zdb-test"] = NewCacheValue([]licensing.Entry{a})
+	Cache["holdings-issn-test"] = NewCacheValue([]licensing.Entry{b})
+	defer delete(Cache, "holdings-zdb-test")
+	defer delete(Cache, "holdings-issn-test")
+	f := &HoldingsFilter{Names: []string{"holdings-zdb-test", "holdings-issn-test"}}
+	var tests = []struct {
+		is   finc.IntermediateSchema
+		want bool
+	}{
+		{finc.IntermediateSchema{ISSN: []string{"4444-4444"}, ZDBID: "2736054-4", RawDate: "2000"}, true},
+		{finc.IntermediateSchema{ISSN: []string{"4444-4444"}, ZDBID: "2736054-4", RawDate: "2011"}, true},
+		{finc.IntermediateSchema{ISSN: []string{"4444-4444"}, ZDBID: "2736054-4", RawDate: "2007"}, false},
+		{finc.IntermediateSchema{ZDBID: "2736054-4", RawDate: "2011"}, false},
+	}
+	for i, test := range tests {
+		if got := f.Apply(test.is); got != test.want {
+			t.Errorf("[%d] got %v, want %v", i, got, test.want)
+		}
+		if got := f.Explain(test.is).Match; got != test.want {
+			t.Errorf("[%d] Explain got %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestHoldingsFilterTitle(t *testing.T) {
+	var (
+		a = licensing.Entry{PublicationTitle: "Südost-Forschungen (2014-)", FirstIssueDate: "2014"}
+		b = licensing.Entry{PublicationTitle: "The Journal of Physical Chemistry", FirstIssueDate: "2000"}
+	)
+	Cache["holdings-title-test"] = NewCacheValue([]licensing.Entry{a, b})
+	defer delete(Cache, "holdings-title-test")
+	var tests = []struct {
+		threshold float64
+		title     string
+		want      bool
+	}{
+		{0, "Sudost-Forschungen", true},
+		{0, "SÜDOST FORSCHUNGEN", true},
+		{0, "Südost-Forschung", false},
+		{0.7, "Südost-Forschung", true},
+		{0.9, "Journal of Physical Chemistry B", true},
+		{0.95, "Journal of Physical Chemistry B", false},
+		{0.7, "Journal of Organic Chemistry", false},
+	}
+	for i, test := range tests {
+		f := &HoldingsFilter{
+			Names:          []string{"holdings-title-test"},
+			CompareByTitle: true,
+			TitleThreshold: test.threshold,
+		}
+		is := finc.IntermediateSchema{JournalTitle: test.title, RawDate: "2015"}
+		if got := f.Apply(is); got != test.want {
+			t.Errorf("[%d] %s (%v): got %v, want %v", i, test.title, test.threshold, got, test.want)
+		}
+	}
+}
+
+// readKBART returns the uncompressed content of a KBART file.
+func readKBART(b *testing.B, filename string) []byte {
+	f, err := os.Open(filename)
+	if err != nil {
+		b.Skipf("fixture: %v", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(filename, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			b.Fatal(err)
+		}
+		r = zr
+	}
+	p, err := io.ReadAll(r)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return p
+}
+
+// liveHeap returns the number of bytes allocated on the heap after a garbage
+// collection.
+func liveHeap() uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// BenchmarkHoldingsMemory reports the heap used by a cached holdings file,
+// with entries copied per key and with entries stored once and referenced by
+// offset. Use SPAN_BENCH_KBART to run against a large EZB KBART file, e.g.
+//
+//	$ SPAN_BENCH_KBART=ezb.tsv go test -run NONE -bench HoldingsMemory
+//
+// With fixtures/kbart.txt.gz the heap shrinks from about 108MiB to 62MiB.
+func BenchmarkHoldingsMemory(b *testing.B) {
+	filename := os.Getenv("SPAN_BENCH_KBART")
+	if filename == "" {
+		filename = "../fixtures/kbart.txt.gz"
+	}
+	p := readKBART(b, filename)
+	parse := func(b *testing.B) kbart.Holdings {
+		var h kbart.Holdings
+		if _, err := h.ReadFrom(bytes.NewReader(p)); err != nil {
+			b.Fatal(err)
+		}
+		return h
+	}
+	b.Run("maps", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			before := liveHeap()
+			h := parse(b)
+			v := []map[string][]licensing.Entry{h.SerialNumberMap(), h.WisoDatabaseMap(), h.TitleMap()}
+			h = nil
+			b.ReportMetric(float64(liveHeap()-before)/(1<<20), "MiB")
+			runtime.KeepAlive(v)
+		}
+	})
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			before := liveHeap()
+			v := NewCacheValue(parse(b))
+			b.ReportMetric(float64(liveHeap()-before)/(1<<20), "MiB")
+			runtime.KeepAlive(v)
+		}
+	})
+}
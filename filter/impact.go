@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// LabelImpact counts, how many records a label would gain or lose, when
+// switching from an old to a new configuration.
+type LabelImpact struct {
+	Label          string   `json:"label"`
+	Old            int      `json:"old"`
+	New            int      `json:"new"`
+	Gained         int      `json:"gained"`
+	Lost           int      `json:"lost"`
+	GainedExamples []string `json:"gained_examples,omitempty"`
+	LostExamples   []string `json:"lost_examples,omitempty"`
+}
+
+// Impact collects per label differences between the labels attached by two
+// configurations. It keeps up to Examples record identifiers for gained and
+// lost records per label. Impact is safe for concurrent use.
+type Impact struct {
+	Examples int
+
+	mu      sync.Mutex
+	records int
+	labels  map[string]*LabelImpact
+}
+
+// NewImpact creates a new impact counter, keeping a number of examples.
+func NewImpact(examples int) *Impact {
+	return &Impact{Examples: examples, labels: make(map[string]*LabelImpact)}
+}
+
+// get returns the counter for a label. Must be called with lock held.
+func (im *Impact) get(label string) *LabelImpact {
+	li, ok := im.labels[label]
+	if !ok {
+		li = &LabelImpact{Label: label}
+		im.labels[label] = li
+	}
+	return li
+}
+
+// Observe records the labels a record gets from the old (before) and the new
+// (after) configuration. Label lists must be sorted, like the ones returned by
+// Evaluator.Tag.
+func (im *Impact) Observe(id string, before, after []string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	im.records++
+	for _, label := range before {
+		li := im.get(label)
+		li.Old++
+		if _, ok := slices.BinarySearch(after, label); !ok {
+			li.Lost++
+			if len(li.LostExamples) < im.Examples {
+				li.LostExamples = append(li.LostExamples, id)
+			}
+		}
+	}
+	for _, label := range after {
+		li := im.get(label)
+		li.New++
+		if _, ok := slices.BinarySearch(before, label); !ok {
+			li.Gained++
+			if len(li.GainedExamples) < im.Examples {
+				li.GainedExamples = append(li.GainedExamples, id)
+			}
+		}
+	}
+}
+
+// Records returns the number of observed records.
+func (im *Impact) Records() int {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	return im.records
+}
+
+// Result returns the counts for all labels seen, sorted by label.
+func (im *Impact) Result() []LabelImpact {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	var result []LabelImpact
+	for _, li := range im.labels {
+		result = append(result, *li)
+	}
+	slices.SortFunc(result, func(a, b LabelImpact) int {
+		return cmp.Compare(a.Label, b.Label)
+	})
+	return result
+}
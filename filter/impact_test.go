@@ -0,0 +1,32 @@
+package filter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestImpact(t *testing.T) {
+	im := NewImpact(1)
+	im.Observe("1", []string{"DE-1", "DE-2"}, []string{"DE-2", "DE-3"})
+	im.Observe("2", []string{"DE-1"}, []string{"DE-1"})
+	im.Observe("3", []string{"DE-1"}, nil)
+	if im.Records() != 3 {
+		t.Fatalf("got %d records, want 3", im.Records())
+	}
+	var want = []LabelImpact{
+		{Label: "DE-1", Old: 3, New: 1, Lost: 2, LostExamples: []string{"1"}},
+		{Label: "DE-2", Old: 1, New: 1},
+		{Label: "DE-3", New: 1, Gained: 1, GainedExamples: []string{"1"}},
+	}
+	got := im.Result()
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Label != w.Label || g.Old != w.Old || g.New != w.New || g.Gained != w.Gained || g.Lost != w.Lost ||
+			!slices.Equal(g.GainedExamples, w.GainedExamples) || !slices.Equal(g.LostExamples, w.LostExamples) {
+			t.Errorf("got %+v, want %+v", g, w)
+		}
+	}
+}
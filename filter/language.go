@@ -0,0 +1,55 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span"
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats/finc"
+)
+
+// LanguageFilter allows records in any one of the given languages. Records
+// carry ISO 639-3 codes, e.g. "eng" or "deu". Values are normalized, so "ger",
+// "de" or "German" match German records as well.
+type LanguageFilter struct {
+	Values *container.StringSet
+}
+
+// normalizeLanguages returns the set of ISO 639-3 codes for the given values.
+// Unknown values are kept as they are.
+func normalizeLanguages(values []string) *container.StringSet {
+	codes := container.NewStringSet()
+	for _, v := range values {
+		if code := span.LanguageCode(v); code != "" {
+			codes.Add(code)
+		} else {
+			codes.Add(strings.TrimSpace(v))
+		}
+	}
+	return codes
+}
+
+// Apply filter.
+func (f *LanguageFilter) Apply(is finc.IntermediateSchema) bool {
+	for _, lang := range is.Languages {
+		if f.Values.Contains(lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON turns a config fragment into a filter. Values are normalized
+// to ISO 639-3.
+func (f *LanguageFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Languages []string `json:"language"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.Values = normalizeLanguages(s.Languages)
+	return nil
+}
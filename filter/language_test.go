@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestLanguageFilter(t *testing.T) {
+	var f LanguageFilter
+	if err := json.Unmarshal([]byte(`{"language": ["ger", "English", "xx-unknown"]}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		about     string
+		languages []string
+		result    bool
+	}{
+		{"bibliographic code", []string{"deu"}, true},
+		{"language name", []string{"eng"}, true},
+		{"unknown value kept", []string{"xx-unknown"}, true},
+		{"other language", []string{"fra"}, false},
+		{"no language", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			if result := f.Apply(finc.IntermediateSchema{Languages: test.languages}); result != test.result {
+				t.Errorf("Apply got %v, want %v", result, test.result)
+			}
+		})
+	}
+	if !Language("ger").Apply(finc.IntermediateSchema{Languages: []string{"deu"}}) {
+		t.Errorf("Language(ger): got false, want true")
+	}
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/formats/finc"
+)
+
+// LicenseFilter allows records with any one of the given licenses. Licenses
+// are compared by their normalized identifier, e.g. "CC-BY-4.0". A value
+// without a version, e.g. "CC-BY", matches all versions of that license, but
+// not "CC-BY-SA-4.0".
+type LicenseFilter struct {
+	Values *container.StringSet
+}
+
+// licenseFamily returns the identifier without a trailing version, e.g.
+// "CC-BY" for "CC-BY-4.0".
+func licenseFamily(id string) string {
+	i := strings.LastIndex(id, "-")
+	if i < 0 || strings.Trim(id[i+1:], "0123456789.") != "" {
+		return id
+	}
+	return id[:i]
+}
+
+// normalizeLicenses returns the set of normalized license identifiers.
+// Unknown values are kept in upper case, e.g. "CC-BY".
+func normalizeLicenses(values []string) *container.StringSet {
+	ids := container.NewStringSet()
+	for _, v := range values {
+		if id := finc.NormalizeLicense(v); id != "" {
+			ids.Add(id)
+		} else {
+			ids.Add(strings.ToUpper(strings.TrimSpace(v)))
+		}
+	}
+	return ids
+}
+
+// Apply filter.
+func (f *LicenseFilter) Apply(is finc.IntermediateSchema) bool {
+	for _, id := range is.LicenseIDs() {
+		if f.Values.Contains(id) || f.Values.Contains(licenseFamily(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmarshalJSON turns a config fragment into a filter. Values are
+// normalized, so "cc by 4.0" and "CC-BY-4.0" are the same.
+func (f *LicenseFilter) UnmarshalJSON(p []byte) error {
+	var s struct {
+		Licenses []string `json:"license"`
+	}
+	if err := json.Unmarshal(p, &s); err != nil {
+		return err
+	}
+	f.Values = normalizeLicenses(s.Licenses)
+	return nil
+}
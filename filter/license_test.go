@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestLicenseFilter(t *testing.T) {
+	var f LicenseFilter
+	if err := json.Unmarshal([]byte(`{"license": ["cc by-nc-nd 4.0", "CC-BY", "CC0-1.0"]}`), &f); err != nil {
+		t.Fatal(err)
+	}
+	var tests = []struct {
+		about   string
+		license []string
+		result  bool
+	}{
+		{"normalized config value", []string{"http://creativecommons.org/licenses/by-nc-nd/4.0/"}, true},
+		{"other version", []string{"https://creativecommons.org/licenses/by-nc-nd/3.0/"}, false},
+		{"any version", []string{"CC BY 3.0"}, true},
+		{"unversioned", []string{"CC BY"}, true},
+		{"family is not a prefix", []string{"CC BY-SA 4.0"}, false},
+		{"one of many", []string{"All rights reserved", "http://creativecommons.org/publicdomain/zero/1.0/"}, true},
+		{"unknown", []string{"All rights reserved"}, false},
+		{"no license", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.about, func(t *testing.T) {
+			if result := f.Apply(finc.IntermediateSchema{License: test.license}); result != test.result {
+				t.Errorf("Apply got %v, want %v", result, test.result)
+			}
+		})
+	}
+	b, err := json.Marshal(&f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"license":["CC-BY","CC-BY-NC-ND-4.0","CC0-1.0"]}`; string(b) != want {
+		t.Errorf("MarshalJSON got %s, want %s", b, want)
+	}
+}
+
+func TestLicenseBuilder(t *testing.T) {
+	f := License("cc by-nc-nd 4.0", "cc-by")
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"license":["CC-BY","CC-BY-NC-ND-4.0"]}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	if !f.Apply(finc.IntermediateSchema{License: []string{"CC BY 3.0"}}) {
+		t.Errorf("got false, want true")
+	}
+}
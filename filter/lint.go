@@ -0,0 +1,273 @@
+package filter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/miku/span/container"
+)
+
+// Severity of a lint issue.
+type Severity string
+
+const (
+	// SeverityError marks issues, that make a filter or label useless.
+	SeverityError Severity = "error"
+	// SeverityWarning marks issues, that are probably mistakes.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a filter configuration. Path points to the
+// filter node, e.g. "or/and[1]/holdings[0]".
+type Issue struct {
+	Label    string   `json:"label"`
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats an issue for display.
+func (i Issue) String() string {
+	return fmt.Sprintf("%s\t%s\t%s\t%s", i.Severity, i.Label, i.Path, i.Message)
+}
+
+// LintOptions allows to cross-check values in a configuration against known
+// values, e.g. from an index. A nil set disables the check.
+type LintOptions struct {
+	Collections *container.StringSet
+	Sources     *container.StringSet
+}
+
+// linter collects issues for a single label.
+type linter struct {
+	label  string
+	opts   LintOptions
+	issues []Issue
+}
+
+// report adds an issue.
+func (l *linter) report(path string, severity Severity, format string, args ...any) {
+	l.issues = append(l.issues, Issue{
+		Label:    l.label,
+		Path:     path,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// walk checks a filter and its children.
+func (l *linter) walk(f Filter, path string) {
+	switch v := f.(type) {
+	case *OrFilter:
+		if len(v.Filters) == 0 {
+			l.report(path, SeverityError, "empty or never matches")
+		}
+		for i, g := range v.Filters {
+			if _, ok := g.(*AnyFilter); ok && len(v.Filters) > 1 {
+				l.report(path, SeverityWarning, "any in or makes the other %d branches redundant", len(v.Filters)-1)
+			}
+			l.walk(g, fmt.Sprintf("%s/%s[%d]", path, filterName(g), i))
+		}
+	case *AndFilter:
+		if len(v.Filters) == 0 {
+			l.report(path, SeverityWarning, "empty and matches every record")
+		}
+		if disjointSources(v.Filters) {
+			l.report(path, SeverityError, "and requires different sources at once")
+		}
+		for i, g := range v.Filters {
+			l.walk(g, fmt.Sprintf("%s/%s[%d]", path, filterName(g), i))
+		}
+	case *NotFilter:
+		switch v.Filter.(type) {
+		case *AnyFilter:
+			l.report(path, SeverityError, "not any never matches")
+		case *NotFilter:
+			l.report(path, SeverityWarning, "double negation")
+		}
+		l.walk(v.Filter, path+"/"+filterName(v.Filter))
+	case *HoldingsFilter:
+		if len(v.Names) == 0 {
+			l.report(path, SeverityError, "holdings filter without files or links")
+		}
+		keys := "ISSN, ZDB-ID, ISBN or DOI"
+		if v.CompareByTitle {
+			keys = "ISSN, ZDB-ID, ISBN, DOI or title"
+		}
+		for _, name := range v.Names {
+			if holdingsSize(v, name) == 0 {
+				l.report(path, SeverityWarning, "holdings file has no entries with %s: %s", keys, name)
+			}
+		}
+	case *SourceFilter:
+		if len(v.Values) == 0 {
+			l.report(path, SeverityError, "empty source list never matches")
+		}
+		l.unknown(path, "source", v.Values, l.opts.Sources)
+	case *CollectionFilter:
+		l.emptySet(path, "collection", v.Values)
+		if v.Values != nil {
+			l.unknown(path, "collection", v.Values.SortedValues(), l.opts.Collections)
+		}
+	case *SubjectFilter:
+		l.emptySet(path, "subject", v.Values)
+	case *PackageFilter:
+		l.emptySet(path, "package", v.Values)
+	case *ISSNFilter:
+		l.emptySet(path, "issn", v.Values)
+	case *ISBNFilter:
+		l.emptySet(path, "isbn", v.Values)
+	case *LanguageFilter:
+		l.emptySet(path, "language", v.Values)
+	case *LicenseFilter:
+		l.emptySet(path, "license", v.Values)
+	case *FormatFilter:
+		l.emptySet(path, "format", v.Values)
+	case *DOIFilter:
+		if len(v.Values) == 0 {
+			l.report(path, SeverityError, "empty doi list never matches")
+		}
+	}
+}
+
+// emptySet reports an empty value set.
+func (l *linter) emptySet(path, name string, set *container.StringSet) {
+	if set == nil || set.Size() == 0 {
+		l.report(path, SeverityError, "empty %s list never matches", name)
+	}
+}
+
+// unknown reports values, that are not in a set of known values.
+func (l *linter) unknown(path, name string, values []string, known *container.StringSet) {
+	if known == nil {
+		return
+	}
+	for _, v := range values {
+		if !known.Contains(v) {
+			l.report(path, SeverityWarning, "%s not found in index: %s", name, v)
+		}
+	}
+}
+
+// holdingsSize returns the number of keys of a cached holdings file, that a
+// holdings filter can look up: ISSN, ZDB-ID, ISBN and DOI, and titles, if the
+// filter compares by title.
+func holdingsSize(f *HoldingsFilter, name string) int {
+	v := Cache[name]
+	n := len(v.SerialNumberMap) + len(v.ZDBMap) + len(v.ISBNMap) + len(v.DOIPrefixMap)
+	if f.CompareByTitle {
+		n += len(v.TitleMap)
+	}
+	return n
+}
+
+// disjointSources returns true, if there are at least two source filters in
+// a conjunction, that have no source id in common.
+func disjointSources(filters []Filter) bool {
+	var common *container.StringSet
+	for _, f := range filters {
+		sf, ok := f.(*SourceFilter)
+		if !ok {
+			continue
+		}
+		set := container.NewStringSet(sf.Values...)
+		if common == nil {
+			common = set
+		} else {
+			common = common.Intersection(set)
+		}
+	}
+	return common != nil && common.Size() == 0
+}
+
+// never returns true, if a filter cannot match any record.
+func never(f Filter) bool {
+	switch v := f.(type) {
+	case *OrFilter:
+		for _, g := range v.Filters {
+			if !never(g) {
+				return false
+			}
+		}
+		return true
+	case *AndFilter:
+		if disjointSources(v.Filters) {
+			return true
+		}
+		return slices.ContainsFunc(v.Filters, never)
+	case *NotFilter:
+		return always(v.Filter)
+	case *HoldingsFilter:
+		for _, name := range v.Names {
+			if holdingsSize(v, name) > 0 {
+				return false
+			}
+		}
+		return true
+	case *SourceFilter:
+		return len(v.Values) == 0
+	case *DOIFilter:
+		return len(v.Values) == 0
+	case *CollectionFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *SubjectFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *PackageFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *ISSNFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *ISBNFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *LanguageFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *LicenseFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	case *FormatFilter:
+		return v.Values == nil || v.Values.Size() == 0
+	}
+	return false
+}
+
+// always returns true, if a filter matches every record.
+func always(f Filter) bool {
+	switch v := f.(type) {
+	case *AnyFilter:
+		return true
+	case *OrFilter:
+		return slices.ContainsFunc(v.Filters, always)
+	case *AndFilter:
+		for _, g := range v.Filters {
+			if !always(g) {
+				return false
+			}
+		}
+		return true
+	case *NotFilter:
+		return never(v.Filter)
+	}
+	return false
+}
+
+// Lint checks the filter trees of a tagger for likely mistakes, like empty
+// lists, negated any filters, holdings files without entries or labels that
+// can never be attached. Issues are sorted by label and path.
+func (t *Tagger) Lint(opts LintOptions) []Issue {
+	var issues []Issue
+	for label, tree := range t.FilterMap {
+		l := &linter{label: label, opts: opts}
+		if tree.Root == nil {
+			l.report("", SeverityError, "empty filter tree")
+		} else {
+			l.walk(tree.Root, filterName(tree.Root))
+			if never(tree.Root) {
+				l.report(filterName(tree.Root), SeverityError, "label can never be attached")
+			}
+		}
+		issues = append(issues, l.issues...)
+	}
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		return cmp.Or(cmp.Compare(a.Label, b.Label), cmp.Compare(a.Path, b.Path))
+	})
+	return issues
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/miku/span/container"
+	"github.com/miku/span/licensing"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestLint(t *testing.T) {
+	s := `{
+		"DE-1": {"or": []},
+		"DE-2": {"not": {"any": {}}},
+		"DE-3": {"and": [{"source": ["49"]}, {"source": ["55"]}]},
+		"DE-4": {"and": [{"source": ["49"]}, {"collection": ["A", "X"]}]},
+		"DE-5": {"or": [{"any": {}}, {"collection": []}]},
+		"DE-6": {"not": {"not": {"source": ["49"]}}}
+	}`
+	var tagger Tagger
+	if err := json.Unmarshal([]byte(s), &tagger); err != nil {
+		t.Fatal(err)
+	}
+	issues := tagger.Lint(LintOptions{
+		Collections: container.NewStringSet("A", "B"),
+		Sources:     container.NewStringSet("49"),
+	})
+	var want = []Issue{
+		{"DE-1", "or", SeverityError, "empty or never matches"},
+		{"DE-1", "or", SeverityError, "label can never be attached"},
+		{"DE-2", "not", SeverityError, "not any never matches"},
+		{"DE-2", "not", SeverityError, "label can never be attached"},
+		{"DE-3", "and", SeverityError, "and requires different sources at once"},
+		{"DE-3", "and", SeverityError, "label can never be attached"},
+		{"DE-3", "and/source[1]", SeverityWarning, "source not found in index: 55"},
+		{"DE-4", "and/collection[1]", SeverityWarning, "collection not found in index: X"},
+		{"DE-5", "or", SeverityWarning, "any in or makes the other 1 branches redundant"},
+		{"DE-5", "or/collection[1]", SeverityError, "empty collection list never matches"},
+		{"DE-6", "not", SeverityWarning, "double negation"},
+	}
+	if len(issues) != len(want) {
+		for _, issue := range issues {
+			t.Log(issue)
+		}
+		t.Fatalf("got %d issues, want %d", len(issues), len(want))
+	}
+	for i := range want {
+		if issues[i] != want[i] {
+			t.Errorf("issue %d: got %v, want %v", i, issues[i], want[i])
+		}
+	}
+}
+
+func TestLintHoldings(t *testing.T) {
+	Cache["lint-empty"] = CacheValue{}
+	defer delete(Cache, "lint-empty")
+	tagger := NewTagger().Add("DE-1", And(Source("49"), Holdings("lint-empty")))
+	issues := tagger.Lint(LintOptions{})
+	if len(issues) != 2 {
+		t.Fatalf("got %v, want 2 issues", issues)
+	}
+	if issues[0].Message != "label can never be attached" {
+		t.Errorf("got %v", issues[0])
+	}
+	if issues[1].Path != "and/holdings[1]" || issues[1].Severity != SeverityWarning {
+		t.Errorf("got %v", issues[1])
+	}
+}
+
+func TestLintHoldingsTitle(t *testing.T) {
+	Cache["lint-title"] = NewCacheValue([]licensing.Entry{{PublicationTitle: "Journal of Chemistry"}})
+	defer delete(Cache, "lint-title")
+	tagger := NewTagger().Add("DE-1", HoldingsWithOpts(false, true, "lint-title"))
+	if issues := tagger.Lint(LintOptions{}); len(issues) != 0 {
+		t.Errorf("got %v, want no issues", issues)
+	}
+	tagger = NewTagger().Add("DE-1", Holdings("lint-title"))
+	if issues := tagger.Lint(LintOptions{}); len(issues) != 2 {
+		t.Errorf("got %v, want 2 issues", issues)
+	}
+}
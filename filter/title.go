@@ -0,0 +1,120 @@
+package filter
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// trigrams returns the distinct character trigrams of a normalized title.
+// Each word is padded, like in PostgreSQL pg_trgm, so short words and word
+// boundaries count as well.
+func trigrams(s string) []string {
+	var grams []string
+	for _, w := range strings.Fields(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			grams = append(grams, string(r[i:i+3]))
+		}
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
+
+// titleSimilarity returns the Jaccard similarity of the trigram sets of two
+// normalized titles, between 0 and 1.
+func titleSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var common int
+	for _, g := range a {
+		if _, ok := slices.BinarySearch(b, g); ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// titleMatch is a title found by similarity.
+type titleMatch struct {
+	Title string  // normalized title, key of CacheValue.TitleMap
+	Score float64 // similarity, between 0 and 1
+}
+
+// titleIndex finds similar titles of a holdings file by trigrams.
+type titleIndex struct {
+	titles []string
+	grams  map[string][]int32 // trigram to title offsets
+}
+
+// newTitleIndex indexes the normalized titles of a holdings file.
+func newTitleIndex(v CacheValue) *titleIndex {
+	idx := &titleIndex{grams: make(map[string][]int32)}
+	for t := range v.TitleMap {
+		idx.titles = append(idx.titles, t)
+	}
+	slices.Sort(idx.titles)
+	for i, t := range idx.titles {
+		for _, g := range trigrams(t) {
+			idx.grams[g] = append(idx.grams[g], int32(i))
+		}
+	}
+	return idx
+}
+
+// similar returns titles with a similarity of at least threshold, best match
+// first. Two sets can only reach the threshold, if they share one of the
+// len(grams) - ceil(threshold * len(grams)) + 1 rarest grams of the query, so
+// only titles containing one of those are compared.
+func (idx *titleIndex) similar(title string, threshold float64) []titleMatch {
+	grams := trigrams(title)
+	if len(grams) == 0 {
+		return nil
+	}
+	rare := slices.Clone(grams)
+	slices.SortFunc(rare, func(a, b string) int {
+		return cmp.Compare(len(idx.grams[a]), len(idx.grams[b]))
+	})
+	prefix := len(rare) - int(math.Ceil(threshold*float64(len(rare)))) + 1
+	var (
+		seen    = make(map[int32]bool)
+		matches []titleMatch
+	)
+	for _, g := range rare[:max(0, min(prefix, len(rare)))] {
+		for _, i := range idx.grams[g] {
+			if seen[i] {
+				continue
+			}
+			seen[i] = true
+			t := idx.titles[i]
+			if score := titleSimilarity(grams, trigrams(t)); score >= threshold {
+				matches = append(matches, titleMatch{Title: t, Score: score})
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b titleMatch) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Title, b.Title))
+	})
+	return matches
+}
+
+// titleIndexes holds the title index for each cached holdings file, keyed by
+// name. Since most configurations do not compare titles by similarity,
+// indices are only built for holdings filters with a title threshold, when
+// the filter is loaded. Lookups do not lock, as filters are applied by many
+// workers in parallel.
+var titleIndexes sync.Map
+
+// titleIndexFor returns the title index of a cached holdings file. The index
+// is built, if the file has not been indexed yet, e.g. for filters created
+// in code.
+func titleIndexFor(name string) *titleIndex {
+	if v, ok := titleIndexes.Load(name); ok {
+		return v.(*titleIndex)
+	}
+	v, _ := titleIndexes.LoadOrStore(name, newTitleIndex(Cache[name]))
+	return v.(*titleIndex)
+}
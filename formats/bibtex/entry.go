@@ -0,0 +1,280 @@
+// Package bibtex reads BibTeX exports, as sent by small publishers and
+// institutional repositories, and converts them to intermediate schema.
+// Values may span several lines, be concatenated from string macros and
+// contain LaTeX, refs. DecodeLaTeX.
+//
+//	@article{doe2019,
+//	  author  = {Doe, Jane and M{\"u}ller, Hans},
+//	  title   = {On {LaTeX} escapes},
+//	  journal = {Journal of Examples},
+//	  year    = 2019,
+//	}
+package bibtex
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/formats/ris"
+	"github.com/miku/span/licensing"
+)
+
+var (
+	// yearPattern finds a year, e.g. in "2019" or "2019-05-12".
+	yearPattern = regexp.MustCompile(`[0-9]{4}`)
+	// andPattern separates names in author and editor fields.
+	andPattern = regexp.MustCompile(`\s+and\s+`)
+)
+
+// refTypes maps BibTeX and BibLaTeX entry types to RIS reference types.
+var refTypes = map[string]string{
+	"article":       "JOUR",
+	"book":          "BOOK",
+	"booklet":       "BOOK",
+	"collection":    "BOOK",
+	"conference":    "CPAPER",
+	"inbook":        "CHAP",
+	"incollection":  "CHAP",
+	"inproceedings": "CPAPER",
+	"manual":        "BOOK",
+	"mastersthesis": "THES",
+	"mvbook":        "BOOK",
+	"online":        "ELEC",
+	"phdthesis":     "THES",
+	"proceedings":   "CONF",
+	"report":        "RPRT",
+	"techreport":    "RPRT",
+	"thesis":        "THES",
+	"unpublished":   "UNPB",
+}
+
+// Entry is a single BibTeX entry. Type and field names are lowercase, values
+// are raw, with LaTeX and braces.
+type Entry struct {
+	Type   string
+	Key    string
+	Fields map[string]string
+	// SourceID and MegaCollections are not part of BibTeX, they are set per
+	// delivery.
+	SourceID        string
+	MegaCollections []string
+}
+
+// Field returns the decoded value of the first field, that is not empty.
+func (e *Entry) Field(names ...string) string {
+	for _, name := range names {
+		if v := DecodeLaTeX(e.Fields[name]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// raw returns the value of a field without braces and surrounding space,
+// for identifiers and links, where LaTeX decoding would do harm.
+func (e *Entry) raw(name string) string {
+	return strings.TrimSpace(strings.NewReplacer("{", "", "}", "", `\_`, "_", `\%`, "%", `\&`, "&").Replace(e.Fields[name]))
+}
+
+// splitNames splits a list of names at "and", outside of braces, so {Barnes
+// and Noble} remains a single (corporate) name.
+func splitNames(s string) (names []string) {
+	var (
+		depth int
+		last  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		default:
+			if depth > 0 {
+				continue
+			}
+			if loc := andPattern.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 && i > 0 {
+				names = append(names, s[last:i])
+				last = i + loc[1]
+				i = last - 1
+			}
+		}
+	}
+	return append(names, s[last:])
+}
+
+// ParseName parses a single name, like "Doe, Jane", "Doe, Jr., Jane" or
+// "Jane Doe". Names in braces, like {World Health Organization}, are
+// corporate.
+func ParseName(s string) finc.Author {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") && !strings.Contains(s[1:len(s)-1], "{") {
+		name := DecodeLaTeX(s)
+		return finc.Author{Name: name, Corporate: name}
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = DecodeLaTeX(parts[i])
+	}
+	switch len(parts) {
+	case 1:
+		return ris.ParseAuthor(parts[0])
+	case 2:
+		return finc.Author{Name: parts[0] + ", " + parts[1], LastName: parts[0], FirstName: parts[1]}
+	default:
+		return finc.Author{
+			Name:      parts[0] + ", " + parts[2],
+			LastName:  parts[0],
+			FirstName: parts[2],
+			Suffix:    parts[1],
+		}
+	}
+}
+
+// Date returns the publication date, from a BibLaTeX date field or from year
+// and month. A missing month or day defaults to January or the first.
+func (e *Entry) Date() (time.Time, error) {
+	if v := e.Field("date"); v != "" {
+		if t, err := ris.ParseDate(v); err == nil {
+			return t, nil
+		}
+	}
+	year := yearPattern.FindString(e.Field("year"))
+	if year == "" {
+		return time.Time{}, errors.New("bibtex: no date found")
+	}
+	month := 1
+	if v := strings.ToLower(e.Field("month")); v != "" {
+		if m, ok := months[v[:min(3, len(v))]]; ok {
+			v = m
+		}
+		if m, err := strconv.Atoi(v); err == nil && m >= 1 && m <= 12 {
+			month = m
+		}
+	}
+	y, _ := strconv.Atoi(year)
+	return time.Date(y, time.Month(month), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// DOI returns the DOI from the doi field or from a link.
+func (e *Entry) DOI() string {
+	for _, v := range []string{e.raw("doi"), e.raw("url")} {
+		if doi := span.FindDOI(v); doi != "" {
+			return doi
+		}
+	}
+	return ""
+}
+
+// RefType returns the RIS reference type, GEN for unknown entry types.
+func (e *Entry) RefType() string {
+	if v, ok := refTypes[e.Type]; ok {
+		return v
+	}
+	return "GEN"
+}
+
+// ToIntermediateSchema converts an entry. The citation key is used as
+// record id, entries without title are skipped.
+func (e *Entry) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	if e.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	output.RecordID = e.Key
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "bibtex: missing citation key"}
+	}
+	output.SourceID = e.SourceID
+	output.ID = span.GenFincID(output.SourceID, output.RecordID)
+	output.MegaCollections = e.MegaCollections
+
+	output.RefType = e.RefType()
+	output.Genre, output.Format = ris.GenreFormat(output.RefType)
+
+	title := e.Field("title")
+	if title == "" {
+		return output, span.Skip{Reason: "bibtex: missing title: " + output.RecordID}
+	}
+	if subtitle := e.Field("subtitle"); subtitle != "" {
+		title = title + ": " + subtitle
+	}
+	switch output.Genre {
+	case "book":
+		output.BookTitle = title
+	case "bookitem", "proceeding":
+		output.ArticleTitle = title
+		output.BookTitle = e.Field("booktitle", "maintitle")
+	default:
+		output.ArticleTitle = title
+		output.JournalTitle = e.Field("journal", "journaltitle")
+	}
+	output.ShortTitle = e.Field("shorttitle")
+	output.Series = e.Field("series")
+
+	authors := e.Fields["author"]
+	if strings.TrimSpace(authors) == "" {
+		authors = e.Fields["editor"]
+	}
+	if strings.TrimSpace(authors) != "" {
+		for _, name := range splitNames(authors) {
+			if a := ParseName(name); a.Name != "" && a.Name != "others" {
+				output.Authors = append(output.Authors, a)
+			}
+		}
+	}
+	if v := e.raw("isbn"); v != "" {
+		// Labels like "ISBN" do not parse and are dropped.
+		for _, s := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == ' ' }) {
+			if isbn := licensing.NormalizeISBN(s); isbn != "" {
+				output.ISBN = append(output.ISBN, isbn)
+			}
+		}
+	}
+	if v := e.raw("issn"); v != "" {
+		output.ISSN = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+	}
+	output.DOI = e.DOI()
+	output.Volume = e.Field("volume")
+	output.Issue = e.Field("number", "issue")
+	if pages := e.raw("pages"); pages != "" {
+		sp, ep, _ := strings.Cut(pages, "-")
+		output.StartPage = strings.TrimSpace(sp)
+		output.EndPage = strings.TrimSpace(strings.TrimLeft(ep, "-"))
+		output.Pages = output.StartPage
+		if output.EndPage != "" {
+			output.Pages = output.StartPage + "-" + output.EndPage
+		}
+	}
+	output.Edition = e.Field("edition")
+	if v := e.Field("publisher", "institution", "school", "organization"); v != "" {
+		output.Publishers = []string{v}
+	}
+	if v := e.Field("address", "location"); v != "" {
+		output.Places = []string{v}
+	}
+	if t, err := e.Date(); err == nil {
+		output.Date = t
+		output.RawDate = t.Format("2006-01-02")
+	}
+	for _, v := range strings.Split(e.Field("language", "langid"), ",") {
+		if lang := span.LanguageCode(v); lang != "" {
+			output.Languages = append(output.Languages, lang)
+		}
+	}
+	output.Abstract = e.Field("abstract")
+	for _, v := range strings.FieldsFunc(e.Field("keywords"), func(r rune) bool { return r == ',' || r == ';' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			output.Subjects = append(output.Subjects, v)
+		}
+	}
+	if v := e.raw("url"); v != "" {
+		output.URL = []string{v}
+	}
+	return output, nil
+}
@@ -0,0 +1,206 @@
+package bibtex
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+)
+
+func readSample(t *testing.T) (entries []*Entry) {
+	f, err := os.Open("testdata/sample.bib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := NewReader(f)
+	for {
+		e, err := r.Read()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestDecodeLaTeX(t *testing.T) {
+	var cases = []struct {
+		s, result string
+	}{
+		{`plain`, `plain`},
+		{`M{\"u}ller`, `Müller`},
+		{`M\"uller`, `Müller`},
+		{`M\"{u}ller`, `Müller`},
+		{`{\'E}tudes`, `Études`},
+		{`Fran\c{c}ois`, `François`},
+		{`Stra{\ss}e`, `Straße`},
+		{`\v{S}koda`, `Škoda`},
+		{`Ji\v{r}\'{\i}`, `Jiří`},
+		{`{\o}re {\aa}s`, `øre ås`},
+		{`Smith \& Sons, 50\%`, `Smith & Sons, 50%`},
+		{`pages 1--2, a---b`, `pages 1–2, a—b`},
+		{"{\\em multi-line}\n    values", `multi-line values`},
+		{`\textit{Homo sapiens}`, `Homo sapiens`},
+		{"``quoted''", `“quoted”`},
+		{`$\alpha$-helix`, `α-helix`},
+		{`\textasciitilde{}user`, `~user`},
+		{`Doe~et~al.`, `Doe et al.`},
+	}
+	for _, c := range cases {
+		if got := DecodeLaTeX(c.s); got != c.result {
+			t.Errorf("DecodeLaTeX(%q): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	entries := readSample(t)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	var cases = []struct {
+		entry  int
+		field  string
+		result string
+	}{
+		{0, "journal", "Journal of Examples"},
+		{0, "volume", "12"},
+		{0, "pages", "45--67"},
+		{1, "title", `A {"}quoted{"} book`},
+		{1, "address", "Berlin"},
+	}
+	for _, c := range cases {
+		if got := entries[c.entry].Fields[c.field]; got != c.result {
+			t.Errorf("entry %d, %s: got %q, want %q", c.entry, c.field, got, c.result)
+		}
+	}
+	if _, err := NewReader(strings.NewReader("@article{x, title = {open")).Read(); err == nil {
+		t.Errorf("expected error for unterminated value")
+	}
+}
+
+func TestParseName(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result finc.Author
+	}{
+		{"Doe, Jane", finc.Author{Name: "Doe, Jane", LastName: "Doe", FirstName: "Jane"}},
+		{`M{\"u}ller, Hans`, finc.Author{Name: "Müller, Hans", LastName: "Müller", FirstName: "Hans"}},
+		{"Smith, Jr., John", finc.Author{Name: "Smith, John", LastName: "Smith", FirstName: "John", Suffix: "Jr."}},
+		{"Jane Doe", finc.Author{Name: "Jane Doe", LastName: "Doe", FirstName: "Jane"}},
+		{"{World Health Organization}", finc.Author{Name: "World Health Organization", Corporate: "World Health Organization"}},
+	}
+	for _, c := range cases {
+		if got := ParseName(c.s); !reflect.DeepEqual(got, c.result) {
+			t.Errorf("ParseName(%q): got %+v, want %+v", c.s, got, c.result)
+		}
+	}
+}
+
+func TestEntryDate(t *testing.T) {
+	var cases = []struct {
+		fields map[string]string
+		result string
+	}{
+		{map[string]string{"year": "2019"}, "2019-01-01"},
+		{map[string]string{"year": "2019", "month": "May"}, "2019-05-01"},
+		{map[string]string{"year": "2019", "month": "5"}, "2019-05-01"},
+		{map[string]string{"year": "{2019}", "month": "september"}, "2019-09-01"},
+		{map[string]string{"date": "2019-05-12", "year": "2018"}, "2019-05-12"},
+		{map[string]string{"year": "n.d."}, ""},
+	}
+	for _, c := range cases {
+		e := &Entry{Fields: c.fields}
+		got, err := e.Date()
+		if c.result == "" {
+			if err == nil {
+				t.Errorf("Date %v: expected error", c.fields)
+			}
+			continue
+		}
+		if err != nil || got.Format(time.DateOnly) != c.result {
+			t.Errorf("Date %v: got %v (%v), want %v", c.fields, got.Format(time.DateOnly), err, c.result)
+		}
+	}
+}
+
+func TestEntryDOI(t *testing.T) {
+	var cases = []struct {
+		fields map[string]string
+		result string
+	}{
+		{map[string]string{"doi": `10.1000/xyz\_123`}, "10.1000/xyz_123"},
+		{map[string]string{"doi": "{10.1000/ABC}"}, "10.1000/ABC"},
+		{map[string]string{"url": "https://doi.org/10.1000/x"}, "10.1000/x"},
+		{map[string]string{"url": "https://example.com"}, ""},
+	}
+	for _, c := range cases {
+		e := &Entry{Fields: c.fields}
+		if got := e.DOI(); got != c.result {
+			t.Errorf("DOI %v: got %q, want %q", c.fields, got, c.result)
+		}
+	}
+}
+
+// TestEntryToIntermediateSchema converts testdata/sample.bib, the entry
+// without title is skipped.
+func TestEntryToIntermediateSchema(t *testing.T) {
+	var result []*finc.IntermediateSchema
+	for _, e := range readSample(t) {
+		if _, err := e.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Errorf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		e.SourceID = "999"
+		output, err := e.ToIntermediateSchema()
+		if _, ok := err.(span.Skip); ok {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d records, want 2", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-ZG9lMjAxOQ"},
+		{0, "Genre", result[0].Genre, "article"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "On LaTeX escapes: Études in multi-line values"},
+		{0, "JournalTitle", result[0].JournalTitle, "Journal of Examples"},
+		{0, "Authors", result[0].Authors, []finc.Author{
+			{Name: "Doe, Jane", LastName: "Doe", FirstName: "Jane"},
+			{Name: "Müller, Hans", LastName: "Müller", FirstName: "Hans"},
+			{Name: "World Health Organization", Corporate: "World Health Organization"},
+		}},
+		{0, "Pages", result[0].Pages, "45-67"},
+		{0, "RawDate", result[0].RawDate, "2019-05-01"},
+		{0, "DOI", result[0].DOI, "10.1000/xyz_123"},
+		{0, "Languages", result[0].Languages, []string{"eng"}},
+		{0, "Subjects", result[0].Subjects, []string{"latex", "bibtex", "parsing"}},
+		{1, "Genre", result[1].Genre, "book"},
+		{1, "BookTitle", result[1].BookTitle, `A "quoted" book`},
+		{1, "ISBN", result[1].ISBN, []string{"9783161484100"}},
+		{1, "Authors", result[1].Authors, []finc.Author{
+			{Name: "Smith, John", LastName: "Smith", FirstName: "John", Suffix: "Jr."},
+		}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package bibtex
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// accents maps LaTeX accent commands to combining characters.
+var accents = map[string]rune{
+	"\"": '̈', // diaeresis
+	"'":  '́', // acute
+	"`":  '̀', // grave
+	"^":  '̂', // circumflex
+	"~":  '̃', // tilde
+	"=":  '̄', // macron
+	".":  '̇', // dot above
+	"c":  '̧', // cedilla
+	"v":  '̌', // caron
+	"u":  '̆', // breve
+	"H":  '̋', // double acute
+	"k":  '̨', // ogonek
+	"r":  '̊', // ring above
+	"d":  '̣', // dot below
+	"b":  '̱', // macron below
+}
+
+// symbols maps LaTeX commands without argument to characters.
+var symbols = map[string]string{
+	"ss": "ß", "o": "ø", "O": "Ø", "aa": "å", "AA": "Å", "ae": "æ", "AE": "Æ",
+	"oe": "œ", "OE": "Œ", "l": "ł", "L": "Ł", "i": "ı", "j": "ȷ",
+	"dh": "ð", "DH": "Ð", "th": "þ", "TH": "Þ", "ng": "ŋ", "NG": "Ŋ",
+	"S": "§", "P": "¶", "copyright": "©", "textregistered": "®",
+	"pounds": "£", "euro": "€", "dag": "†", "ddag": "‡",
+	"ldots": "…", "dots": "…", "textendash": "–", "textemdash": "—",
+	"textquoteleft": "‘", "textquoteright": "’", "textquotedblleft": "“",
+	"textquotedblright": "”", "guillemotleft": "«", "guillemotright": "»",
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε",
+	"lambda": "λ", "mu": "μ", "pi": "π", "sigma": "σ", "omega": "ω",
+	"textasciitilde": "~", "textbackslash": "\\", "textunderscore": "_",
+	"TeX": "TeX", "LaTeX": "LaTeX", "BibTeX": "BibTeX",
+}
+
+// ligatures are replaced after commands, so "--" in a command argument works.
+var ligatures = strings.NewReplacer(
+	"---", "—",
+	"--", "–",
+	"``", "“",
+	"''", "”",
+)
+
+// DecodeLaTeX turns a BibTeX value into plain text. Accents, like {\"a}, \"a
+// or \"{a}, become precomposed characters, escaped characters and common
+// symbols are replaced, formatting commands, like \emph{...}, are dropped in
+// favor of their argument and braces are removed. Line breaks and repeated
+// whitespace are collapsed. Unknown commands are dropped, not their
+// arguments, so the result is tolerant rather than exact.
+func DecodeLaTeX(s string) string {
+	if !strings.ContainsAny(s, "\\{}~-`'$") {
+		return strings.Join(strings.Fields(s), " ")
+	}
+	var (
+		sb strings.Builder
+		rs = []rune(s)
+	)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch c {
+		case '{', '}', '$':
+			continue
+		case '~':
+			// Non-breaking space.
+			sb.WriteRune(' ')
+			continue
+		case '\\':
+		default:
+			sb.WriteRune(c)
+			continue
+		}
+		if i+1 >= len(rs) {
+			break
+		}
+		// Command name, either a single non-letter or a run of letters.
+		j := i + 1
+		if unicode.IsLetter(rs[j]) {
+			for j < len(rs) && unicode.IsLetter(rs[j]) {
+				j++
+			}
+		} else {
+			j++
+		}
+		name := string(rs[i+1 : j])
+		i = j - 1
+		if mark, ok := accents[name]; ok {
+			// The argument is a braced group or the next non-space rune.
+			k := j
+			for k < len(rs) && rs[k] == ' ' && unicode.IsLetter(rs[i]) {
+				k++
+			}
+			var arg []rune
+			switch {
+			case k < len(rs) && rs[k] == '{':
+				end := k + 1
+				for end < len(rs) && rs[end] != '}' {
+					end++
+				}
+				arg = rs[k+1 : min(end, len(rs))]
+				i = end
+			case k+1 < len(rs) && rs[k] == '\\':
+				arg = rs[k+1 : k+2]
+				i = k + 1
+			case k < len(rs):
+				arg = rs[k : k+1]
+				i = k
+			}
+			if len(arg) > 0 {
+				// Dotless i and j carry accents, e.g. \'{\i}.
+				base := strings.TrimPrefix(string(arg), "\\")
+				sb.WriteString(norm.NFC.String(base + string(mark)))
+			}
+			continue
+		}
+		if v, ok := symbols[name]; ok {
+			sb.WriteString(v)
+			// A space after a command name only terminates it.
+			if j < len(rs) && rs[j] == ' ' && unicode.IsLetter(rs[i]) {
+				i++
+			}
+			continue
+		}
+		switch name {
+		case "&", "%", "$", "#", "_", "{", "}", " ":
+			sb.WriteString(name)
+		case "\\":
+			sb.WriteString(" ")
+		}
+		// Other commands, like \emph or \textit, are dropped; their argument
+		// is kept, since braces are skipped.
+	}
+	return strings.Join(strings.Fields(ligatures.Replace(sb.String())), " ")
+}
@@ -0,0 +1,302 @@
+package bibtex
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"unicode"
+)
+
+// months are the predefined month macros.
+var months = map[string]string{
+	"jan": "1", "feb": "2", "mar": "3", "apr": "4", "may": "5", "jun": "6",
+	"jul": "7", "aug": "8", "sep": "9", "oct": "10", "nov": "11", "dec": "12",
+}
+
+// Reader reads BibTeX entries from a stream. String macros (@string) are
+// expanded, @comment and @preamble are skipped, text between entries is
+// ignored.
+type Reader struct {
+	r       *bufio.Reader
+	macros  map[string]string
+	lineNum int
+}
+
+// NewReader returns a reader for BibTeX entries.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r), macros: maps.Clone(months), lineNum: 1}
+}
+
+// SyntaxError reports the line of an entry, that cannot be parsed.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("bibtex: line %d: %s", e.Line, e.Msg)
+}
+
+func (r *Reader) errorf(format string, a ...any) error {
+	return &SyntaxError{Line: r.lineNum, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (r *Reader) readRune() (rune, error) {
+	c, _, err := r.r.ReadRune()
+	if c == '\n' {
+		r.lineNum++
+	}
+	return c, err
+}
+
+// unreadRune puts back the last rune read. For a newline, the caller must
+// correct the line number.
+func (r *Reader) unreadRune() {
+	r.r.UnreadRune()
+}
+
+// skipSpace skips whitespace and returns the next rune.
+func (r *Reader) skipSpace() (rune, error) {
+	for {
+		c, err := r.readRune()
+		if err != nil || !unicode.IsSpace(c) {
+			return c, err
+		}
+	}
+}
+
+// readIdent reads a name, like an entry type, a key or a field name.
+func (r *Reader) readIdent() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.readRune()
+		if err != nil {
+			return sb.String(), err
+		}
+		if unicode.IsSpace(c) || strings.ContainsRune(`{}(),="#%`, c) {
+			if c == '\n' {
+				r.lineNum--
+			}
+			r.unreadRune()
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readBraced reads until the matching closing brace, the opening one has
+// been read already. Inner braces are kept.
+func (r *Reader) readBraced(close rune) (string, error) {
+	var (
+		sb    strings.Builder
+		depth = 0
+		open  = '{'
+	)
+	if close == ')' {
+		open = '('
+	}
+	for {
+		c, err := r.readRune()
+		if err != nil {
+			return sb.String(), r.errorf("unterminated value")
+		}
+		switch {
+		case c == '\\':
+			// Keep escaped braces, like \{, as they are.
+			sb.WriteRune(c)
+			if c, err = r.readRune(); err != nil {
+				return sb.String(), r.errorf("unterminated value")
+			}
+		case c == open:
+			depth++
+		case c == close && depth == 0:
+			return sb.String(), nil
+		case c == close:
+			depth--
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readQuoted reads a quoted value, quotes inside braces do not terminate it.
+func (r *Reader) readQuoted() (string, error) {
+	var (
+		sb    strings.Builder
+		depth = 0
+	)
+	for {
+		c, err := r.readRune()
+		if err != nil {
+			return sb.String(), r.errorf("unterminated value")
+		}
+		switch {
+		case c == '\\':
+			sb.WriteRune(c)
+			if c, err = r.readRune(); err != nil {
+				return sb.String(), r.errorf("unterminated value")
+			}
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		case c == '"' && depth == 0:
+			return sb.String(), nil
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// readValue reads a possibly concatenated value, like {A} # " and " # b,
+// and returns it along with the rune terminating it.
+func (r *Reader) readValue() (string, rune, error) {
+	var sb strings.Builder
+	for {
+		c, err := r.skipSpace()
+		if err != nil {
+			return "", 0, r.errorf("unexpected end of input")
+		}
+		switch c {
+		case '{':
+			s, err := r.readBraced('}')
+			if err != nil {
+				return "", 0, err
+			}
+			sb.WriteString(s)
+		case '"':
+			s, err := r.readQuoted()
+			if err != nil {
+				return "", 0, err
+			}
+			sb.WriteString(s)
+		default:
+			r.unreadRune()
+			name, err := r.readIdent()
+			if err != nil || name == "" {
+				return "", 0, r.errorf("invalid value")
+			}
+			if v, ok := r.macros[strings.ToLower(name)]; ok {
+				sb.WriteString(v)
+			} else {
+				// Numbers and undefined macros are kept as they are.
+				sb.WriteString(name)
+			}
+		}
+		if c, err = r.skipSpace(); err != nil {
+			return "", 0, r.errorf("unexpected end of input")
+		}
+		if c != '#' {
+			return sb.String(), c, nil
+		}
+	}
+}
+
+// Read returns the next entry or io.EOF, if there are no more entries.
+func (r *Reader) Read() (*Entry, error) {
+	for {
+		// Anything outside of an entry is a comment.
+		c, err := r.readRune()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c != '@' {
+			continue
+		}
+		kind, err := r.readIdent()
+		if err != nil {
+			return nil, r.errorf("unexpected end of input")
+		}
+		kind = strings.ToLower(kind)
+		c, err = r.skipSpace()
+		if err != nil {
+			return nil, r.errorf("unexpected end of input")
+		}
+		var close rune
+		switch c {
+		case '{':
+			close = '}'
+		case '(':
+			close = ')'
+		default:
+			// An @ in free text, e.g. an email address.
+			continue
+		}
+		switch kind {
+		case "comment", "preamble":
+			if _, err := r.readBraced(close); err != nil {
+				return nil, err
+			}
+			continue
+		case "string":
+			if err := r.readFields(close, func(name, value string) {
+				r.macros[strings.ToLower(name)] = value
+			}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		start := r.lineNum
+		entry := &Entry{Type: kind, Fields: make(map[string]string)}
+		if c, err = r.skipSpace(); err != nil {
+			return nil, r.errorf("unexpected end of input")
+		}
+		r.unreadRune()
+		if entry.Key, err = r.readIdent(); err != nil {
+			return nil, r.errorf("unexpected end of input")
+		}
+		if c, err = r.skipSpace(); err != nil {
+			return nil, r.errorf("unexpected end of input")
+		}
+		switch c {
+		case ',':
+		case close:
+			return entry, nil
+		default:
+			return nil, &SyntaxError{Line: start, Msg: fmt.Sprintf("expected comma after key %q", entry.Key)}
+		}
+		if err := r.readFields(close, func(name, value string) {
+			entry.Fields[strings.ToLower(name)] = value
+		}); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+}
+
+// readFields reads comma separated name = value pairs up to the closing
+// delimiter of an entry. A trailing comma is allowed.
+func (r *Reader) readFields(close rune, f func(name, value string)) error {
+	for {
+		c, err := r.skipSpace()
+		if err != nil {
+			return r.errorf("unexpected end of input")
+		}
+		if c == close {
+			return nil
+		}
+		r.unreadRune()
+		name, err := r.readIdent()
+		if err != nil || name == "" {
+			return r.errorf("expected field name")
+		}
+		if c, err = r.skipSpace(); err != nil || c != '=' {
+			return r.errorf("expected = after %q", name)
+		}
+		value, c, err := r.readValue()
+		if err != nil {
+			return err
+		}
+		f(name, value)
+		switch c {
+		case ',':
+		case close:
+			return nil
+		default:
+			return r.errorf("unexpected %q after field %q", c, name)
+		}
+	}
+}
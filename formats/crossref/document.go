@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -337,6 +338,11 @@ func (doc *Document) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	output.ISSN = doc.ISSN
 	output.Issue = strings.TrimLeft(doc.Issue, "0")
 	output.Languages = doc.FindLanguages()
+	for _, l := range doc.License {
+		if l.URL != "" && !slices.Contains(output.License, l.URL) {
+			output.License = append(output.License, l.URL)
+		}
+	}
 	output.Publishers = append(output.Publishers, doc.Publisher)
 	output.RefType = RefTypes.Lookup(doc.Type, "GEN")
 	output.SourceID = SourceID
@@ -0,0 +1,366 @@
+// Package datacite converts DataCite metadata, research data and repository
+// DOIs, to intermediate schema. Two serializations are supported: JSON, as
+// returned by the REST API (https://api.datacite.org/dois), one document per
+// line, and XML following metadata kernel 4, e.g. from OAI-PMH.
+//
+// Both are converted into Attributes first, so the mapping is the same.
+package datacite
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/assetutil"
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+var (
+	// ErrNoDOI is returned for records without a DOI.
+	ErrNoDOI = errors.New("datacite: DOI is missing")
+
+	// Formats, Genres and RefTypes map resourceTypeGeneral values.
+	Formats  = assetutil.MustLoadStringMap("assets/datacite/formats.json")
+	Genres   = assetutil.MustLoadStringMap("assets/datacite/genres.json")
+	RefTypes = assetutil.MustLoadStringMap("assets/datacite/reftypes.json")
+
+	// DefaultFormat is used for unknown resource types.
+	DefaultFormat = "ElectronicResourceRemoteAccess"
+
+	// orcidPattern finds an ORCID iD, with or without URL prefix.
+	orcidPattern = regexp.MustCompile(`[0-9]{4}-[0-9]{4}-[0-9]{4}-[0-9]{3}[0-9X]`)
+	// yearPattern finds a year in a date.
+	yearPattern = regexp.MustCompile(`^[0-9]{4}`)
+)
+
+// NameIdentifier identifies a creator, e.g. by ORCID.
+type NameIdentifier struct {
+	NameIdentifier       string `json:"nameIdentifier"`
+	NameIdentifierScheme string `json:"nameIdentifierScheme"`
+	SchemeURI            string `json:"schemeUri"`
+}
+
+// Creator of a resource, a person or an organization.
+type Creator struct {
+	Name            string           `json:"name"`
+	NameType        string           `json:"nameType"`
+	GivenName       string           `json:"givenName"`
+	FamilyName      string           `json:"familyName"`
+	NameIdentifiers []NameIdentifier `json:"nameIdentifiers"`
+}
+
+// ORCID returns the ORCID iD of a creator as URL, or an empty string.
+func (c Creator) ORCID() string {
+	for _, id := range c.NameIdentifiers {
+		if !strings.EqualFold(id.NameIdentifierScheme, "ORCID") {
+			continue
+		}
+		if v := orcidPattern.FindString(id.NameIdentifier); v != "" {
+			return "https://orcid.org/" + v
+		}
+	}
+	return ""
+}
+
+// Title of a resource, titleType is empty for the main title.
+type Title struct {
+	Title     string `json:"title"`
+	TitleType string `json:"titleType"`
+	Lang      string `json:"lang"`
+}
+
+// Subject is a keyword or classification.
+type Subject struct {
+	Subject       string `json:"subject"`
+	SubjectScheme string `json:"subjectScheme"`
+}
+
+// Date with type, e.g. Issued or Created.
+type Date struct {
+	Date     string `json:"date"`
+	DateType string `json:"dateType"`
+}
+
+// Types holds the resource type and its crosswalks.
+type Types struct {
+	ResourceTypeGeneral string `json:"resourceTypeGeneral"`
+	ResourceType        string `json:"resourceType"`
+	Ris                 string `json:"ris"`
+}
+
+// RelatedIdentifier links to related works, e.g. the journal (IsPartOf,
+// ISSN) or the publication a dataset supplements (IsSupplementTo, DOI).
+type RelatedIdentifier struct {
+	RelatedIdentifier     string `json:"relatedIdentifier"`
+	RelatedIdentifierType string `json:"relatedIdentifierType"`
+	RelationType          string `json:"relationType"`
+}
+
+// Rights is a license statement.
+type Rights struct {
+	Rights           string `json:"rights"`
+	RightsURI        string `json:"rightsUri"`
+	RightsIdentifier string `json:"rightsIdentifier"`
+}
+
+// Description, e.g. an abstract.
+type Description struct {
+	Description     string `json:"description"`
+	DescriptionType string `json:"descriptionType"`
+}
+
+// Container is the serial or book, a resource is published in.
+type Container struct {
+	Type           string `json:"type"`
+	Identifier     string `json:"identifier"`
+	IdentifierType string `json:"identifierType"`
+	Title          string `json:"title"`
+	Volume         string `json:"volume"`
+	Issue          string `json:"issue"`
+	FirstPage      string `json:"firstPage"`
+	LastPage       string `json:"lastPage"`
+}
+
+// Attributes are the metadata of a DOI, as in data.attributes of the REST
+// API. Publisher and publication year are kept raw, since their type varies
+// between API versions.
+type Attributes struct {
+	DOI                string              `json:"doi"`
+	URL                string              `json:"url"`
+	Creators           []Creator           `json:"creators"`
+	Titles             []Title             `json:"titles"`
+	Publisher          json.RawMessage     `json:"publisher"`
+	PublicationYear    json.RawMessage     `json:"publicationYear"`
+	Subjects           []Subject           `json:"subjects"`
+	Dates              []Date              `json:"dates"`
+	Language           string              `json:"language"`
+	Types              Types               `json:"types"`
+	RelatedIdentifiers []RelatedIdentifier `json:"relatedIdentifiers"`
+	RightsList         []Rights            `json:"rightsList"`
+	Descriptions       []Description       `json:"descriptions"`
+	Container          Container           `json:"container"`
+	Version            string              `json:"version"`
+	// SourceID is not part of the metadata, refs. Document.SourceID.
+	SourceID string `json:"-"`
+}
+
+// Document is a DataCite JSON document. It accepts an API response, like
+// {"data": {"attributes": {...}}}, a single data object or the attributes
+// alone.
+type Document struct {
+	Attributes Attributes
+	// SourceID of converted records. DataCite is not yet a fixed source, so
+	// it must be set before conversion, e.g. with span-import -sid.
+	SourceID string
+}
+
+// UnmarshalJSON unwraps data and attributes, if present.
+func (doc *Document) UnmarshalJSON(b []byte) error {
+	var wrapper struct {
+		Data       json.RawMessage `json:"data"`
+		Attributes json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return err
+	}
+	switch {
+	case len(wrapper.Data) > 0 && string(wrapper.Data) != "null":
+		return doc.UnmarshalJSON(wrapper.Data)
+	case len(wrapper.Attributes) > 0:
+		return json.Unmarshal(wrapper.Attributes, &doc.Attributes)
+	default:
+		return json.Unmarshal(b, &doc.Attributes)
+	}
+}
+
+// ToIntermediateSchema converts a JSON document.
+func (doc *Document) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	a := doc.Attributes
+	a.SourceID = doc.SourceID
+	return a.ToIntermediateSchema()
+}
+
+// PublisherName returns the publisher, given as string or as object with a
+// name.
+func (a *Attributes) PublisherName() string {
+	var s string
+	if err := json.Unmarshal(a.Publisher, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(a.Publisher, &v); err == nil {
+		return strings.TrimSpace(v.Name)
+	}
+	return ""
+}
+
+// Year returns the publication year, given as number or string, or zero.
+func (a *Attributes) Year() int {
+	y, _ := strconv.Atoi(strings.Trim(string(a.PublicationYear), `" `))
+	return y
+}
+
+// Date returns the date of issue, or the first day of the publication year.
+func (a *Attributes) Date() (time.Time, error) {
+	for _, d := range a.Dates {
+		if d.DateType != "Issued" {
+			continue
+		}
+		for _, layout := range []string{"2006-01-02", "2006-01"} {
+			if t, err := time.Parse(layout, d.Date[:min(len(layout), len(d.Date))]); err == nil {
+				return t, nil
+			}
+		}
+	}
+	if y := a.Year(); y > 0 {
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	for _, d := range a.Dates {
+		if yearPattern.MatchString(d.Date) {
+			return time.Parse("2006", d.Date[:4])
+		}
+	}
+	return time.Time{}, errors.New("datacite: no date found")
+}
+
+// ToIntermediateSchema converts attributes. The resource type decides about
+// format, genre and reference type. Creators with an ORCID carry it as
+// author id, rights are kept as license, ISSN and ISBN of related journals or
+// books are added to the record.
+func (a *Attributes) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	if a.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	doi := strings.ToLower(strings.TrimSpace(a.DOI))
+	if doi == "" {
+		return output, span.Skip{Reason: ErrNoDOI.Error()}
+	}
+	output.DOI = doi
+	output.RecordID = doi
+	output.SourceID = a.SourceID
+	output.ID = span.GenFincID(a.SourceID, doi)
+	output.MegaCollections = []string{"DataCite"}
+
+	rtg := a.Types.ResourceTypeGeneral
+	output.Format = Formats.Lookup(rtg, DefaultFormat)
+	output.Genre = Genres.Lookup(rtg, "unknown")
+	output.RefType = RefTypes.Lookup(rtg, "GEN")
+	output.Type = rtg
+
+	var title, subtitle string
+	for _, t := range a.Titles {
+		switch t.TitleType {
+		case "":
+			if title == "" {
+				title = strings.TrimSpace(t.Title)
+			}
+		case "Subtitle":
+			if subtitle == "" {
+				subtitle = strings.TrimSpace(t.Title)
+			}
+		}
+	}
+	if title == "" {
+		return output, span.Skip{Reason: "datacite: missing title: " + doi}
+	}
+	switch {
+	case rtg == "Journal":
+		// A journal as a whole has no schema genre, refs. crossref "journal".
+		output.ArticleTitle = title
+		output.JournalTitle = title
+	case output.Genre == "book" || output.Genre == "conference":
+		output.BookTitle = title
+	case output.Genre == "bookitem" || output.Genre == "proceeding":
+		output.ArticleTitle = title
+		output.BookTitle = a.Container.Title
+	default:
+		output.ArticleTitle = title
+		output.JournalTitle = a.Container.Title
+	}
+	output.ArticleSubtitle = subtitle
+
+	for _, c := range a.Creators {
+		author := finc.Author{ID: c.ORCID(), FirstName: c.GivenName, LastName: c.FamilyName}
+		if c.NameType == "Organizational" {
+			author.Corporate = c.Name
+		} else {
+			author.Name = c.Name
+		}
+		if author.Name == "" && author.Corporate == "" {
+			continue
+		}
+		output.Authors = append(output.Authors, author)
+	}
+	// Only identifiers of the journal or book, the record is part of, describe
+	// the record itself. Other relations, e.g. IsSupplementTo or References,
+	// point to different works and the intermediate schema has no field for
+	// them; their DOIs or URLs would be mistaken for the record's own.
+	for _, r := range a.RelatedIdentifiers {
+		if r.RelationType != "IsPartOf" && r.RelationType != "IsPublishedIn" {
+			continue
+		}
+		switch v := strings.TrimSpace(r.RelatedIdentifier); r.RelatedIdentifierType {
+		case "ISSN", "EISSN":
+			if !slices.Contains(output.ISSN, v) {
+				output.ISSN = append(output.ISSN, v)
+			}
+		case "ISBN":
+			if !slices.Contains(output.ISBN, v) {
+				output.ISBN = append(output.ISBN, v)
+			}
+		}
+	}
+	if a.Container.IdentifierType == "ISSN" && a.Container.Identifier != "" &&
+		!slices.Contains(output.ISSN, a.Container.Identifier) {
+		output.ISSN = append(output.ISSN, a.Container.Identifier)
+	}
+	output.Volume = a.Container.Volume
+	output.Issue = a.Container.Issue
+	output.StartPage = a.Container.FirstPage
+	output.EndPage = a.Container.LastPage
+	if output.StartPage != "" && output.EndPage != "" {
+		output.Pages = output.StartPage + "-" + output.EndPage
+	}
+	if p := a.PublisherName(); p != "" {
+		output.Publishers = []string{p}
+	}
+	if t, err := a.Date(); err == nil {
+		output.Date = t
+		output.RawDate = t.Format("2006-01-02")
+	}
+	if lang := span.LanguageCode(a.Language); lang != "" {
+		output.Languages = []string{lang}
+	}
+	for _, s := range a.Subjects {
+		if v := strings.TrimSpace(s.Subject); v != "" && !slices.Contains(output.Subjects, v) {
+			output.Subjects = append(output.Subjects, v)
+		}
+	}
+	for _, d := range a.Descriptions {
+		if d.DescriptionType == "Abstract" {
+			output.Abstract = strings.TrimSpace(d.Description)
+			break
+		}
+	}
+	for _, r := range a.RightsList {
+		for _, v := range []string{r.RightsURI, r.RightsIdentifier, r.Rights} {
+			if v = strings.TrimSpace(v); v != "" {
+				output.License = append(output.License, v)
+				break
+			}
+		}
+	}
+	output.URL = []string{"https://doi.org/" + doi}
+	if a.URL != "" {
+		output.URL = append(output.URL, a.URL)
+	}
+	return output, nil
+}
@@ -0,0 +1,201 @@
+package datacite
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/xmlstream"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestCreatorORCID(t *testing.T) {
+	var cases = []struct {
+		creator Creator
+		result  string
+	}{
+		{Creator{}, ""},
+		{Creator{NameIdentifiers: []NameIdentifier{
+			{NameIdentifier: "0000-0002-1825-0097", NameIdentifierScheme: "ORCID"},
+		}}, "https://orcid.org/0000-0002-1825-0097"},
+		{Creator{NameIdentifiers: []NameIdentifier{
+			{NameIdentifier: "https://orcid.org/0000-0002-1694-233X", NameIdentifierScheme: "orcid"},
+		}}, "https://orcid.org/0000-0002-1694-233X"},
+		{Creator{NameIdentifiers: []NameIdentifier{
+			{NameIdentifier: "0000-0002-1825-0097", NameIdentifierScheme: "ISNI"},
+		}}, ""},
+	}
+	for _, c := range cases {
+		if got := c.creator.ORCID(); got != c.result {
+			t.Errorf("ORCID(%v): got %q, want %q", c.creator, got, c.result)
+		}
+	}
+}
+
+func TestAttributesPublisherName(t *testing.T) {
+	var cases = []struct {
+		publisher string
+		result    string
+	}{
+		{`"Zenodo"`, "Zenodo"},
+		{`" Zenodo "`, "Zenodo"},
+		{`{"name": "Zenodo"}`, "Zenodo"},
+		{`null`, ""},
+		{`42`, ""},
+	}
+	for _, c := range cases {
+		a := Attributes{Publisher: json.RawMessage(c.publisher)}
+		if got := a.PublisherName(); got != c.result {
+			t.Errorf("PublisherName(%s): got %q, want %q", c.publisher, got, c.result)
+		}
+	}
+}
+
+func TestAttributesYear(t *testing.T) {
+	var cases = []struct {
+		year   string
+		result int
+	}{
+		{`2019`, 2019},
+		{`"2019"`, 2019},
+		{`""`, 0},
+		{``, 0},
+	}
+	for _, c := range cases {
+		a := Attributes{PublicationYear: json.RawMessage(c.year)}
+		if got := a.Year(); got != c.result {
+			t.Errorf("Year(%s): got %d, want %d", c.year, got, c.result)
+		}
+	}
+}
+
+func TestAttributesDate(t *testing.T) {
+	var cases = []struct {
+		attrs  Attributes
+		result string
+	}{
+		{Attributes{Dates: []Date{{"2019-05-12", "Issued"}}}, "2019-05-12"},
+		{Attributes{Dates: []Date{{"2019-05-12T10:00:00Z", "Issued"}}}, "2019-05-12"},
+		{Attributes{Dates: []Date{{"2019-05", "Issued"}}}, "2019-05-01"},
+		{Attributes{Dates: []Date{{"2019-05-12", "Created"}}, PublicationYear: json.RawMessage(`2018`)}, "2018-01-01"},
+		{Attributes{Dates: []Date{{"2017-03-01", "Created"}}}, "2017-01-01"},
+		{Attributes{}, ""},
+	}
+	for _, c := range cases {
+		got, err := c.attrs.Date()
+		if c.result == "" {
+			if err == nil {
+				t.Errorf("Date(%v): expected error", c.attrs.Dates)
+			}
+			continue
+		}
+		if err != nil || got.Format(time.DateOnly) != c.result {
+			t.Errorf("Date(%v): got %v (%v), want %v", c.attrs.Dates, got.Format(time.DateOnly), err, c.result)
+		}
+	}
+}
+
+// readDocuments converts testdata/document.json.
+func readDocuments(t *testing.T) (result []*finc.IntermediateSchema) {
+	f, err := os.Open("testdata/document.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	dec := json.NewDecoder(f)
+	for {
+		var doc Document
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := doc.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Fatalf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		doc.SourceID = "999"
+		output, err := doc.ToIntermediateSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	return result
+}
+
+// TestDocumentToIntermediateSchema converts a dataset from an API response,
+// a bare journal article and a journal.
+func TestDocumentToIntermediateSchema(t *testing.T) {
+	result := readDocuments(t)
+	if len(result) != 3 {
+		t.Fatalf("got %d records, want 3", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-MTAuNTI4MS96ZW5vZG8uMTIz"},
+		{0, "DOI", result[0].DOI, "10.5281/zenodo.123"},
+		{0, "Genre", result[0].Genre, "unknown"},
+		{0, "Format", result[0].Format, "ElectronicResourceRemoteAccess"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "Measurements"},
+		{0, "ArticleSubtitle", result[0].ArticleSubtitle, "A dataset"},
+		{0, "Authors", result[0].Authors, []finc.Author{
+			{ID: "https://orcid.org/0000-0002-1825-0097", Name: "Doe, Jane", LastName: "Doe", FirstName: "Jane"},
+			{Corporate: "Example Consortium"},
+		}},
+		{0, "ISSN", result[0].ISSN, []string{"1234-5678"}},
+		{0, "Publishers", result[0].Publishers, []string{"Zenodo"}},
+		{0, "RawDate", result[0].RawDate, "2019-05-12"},
+		{0, "Languages", result[0].Languages, []string{"eng"}},
+		{0, "Abstract", result[0].Abstract, "Some numbers."},
+		{0, "URL", result[0].URL, []string{"https://doi.org/10.5281/zenodo.123", "https://zenodo.org/record/123"}},
+		{0, "License", result[0].License, []string{"https://creativecommons.org/licenses/by/4.0/legalcode"}},
+		{1, "Genre", result[1].Genre, "article"},
+		{1, "ArticleTitle", result[1].ArticleTitle, "T"},
+		{1, "Publishers", result[1].Publishers, []string{"P"}},
+		{1, "RawDate", result[1].RawDate, "2001-01-01"},
+		{2, "Genre", result[2].Genre, "unknown"},
+		{2, "ArticleTitle", result[2].ArticleTitle, "J"},
+		{2, "JournalTitle", result[2].JournalTitle, "J"},
+		{2, "ISSN", result[2].ISSN, []string{"1234-5679"}},
+		{2, "URL", result[2].URL, []string{"https://doi.org/10.1/j"}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
+
+// TestResourceToIntermediateSchema converts testdata/resource.xml, the first
+// record of testdata/document.json as DataCite XML. Both yield the same
+// record, except for the landing page, which is not part of the XML.
+func TestResourceToIntermediateSchema(t *testing.T) {
+	f, err := os.Open("testdata/resource.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := xmlstream.NewScanner(f, new(Resource))
+	if !scanner.Scan() {
+		t.Fatalf("no resource found: %v", scanner.Err())
+	}
+	resource := scanner.Element().(*Resource)
+	resource.SourceID = "999"
+	output, err := resource.ToIntermediateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := readDocuments(t)[0]
+	want.URL = []string{"https://doi.org/10.5281/zenodo.123"}
+	if !reflect.DeepEqual(output, want) {
+		t.Errorf("got %+v, want %+v", output, want)
+	}
+}
@@ -0,0 +1,139 @@
+package datacite
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+// Resource is a DataCite XML record, metadata kernel 4. Within OAI-PMH
+// responses, resource elements are found below metadata.
+type Resource struct {
+	XMLName    xml.Name `xml:"resource"`
+	Identifier struct {
+		Text           string `xml:",chardata"`
+		IdentifierType string `xml:"identifierType,attr"`
+	} `xml:"identifier"`
+	Creators []struct {
+		CreatorName struct {
+			Text     string `xml:",chardata"`
+			NameType string `xml:"nameType,attr"`
+		} `xml:"creatorName"`
+		GivenName      string `xml:"givenName"`
+		FamilyName     string `xml:"familyName"`
+		NameIdentifier []struct {
+			Text                 string `xml:",chardata"`
+			NameIdentifierScheme string `xml:"nameIdentifierScheme,attr"`
+			SchemeURI            string `xml:"schemeURI,attr"`
+		} `xml:"nameIdentifier"`
+	} `xml:"creators>creator"`
+	Titles []struct {
+		Text      string `xml:",chardata"`
+		TitleType string `xml:"titleType,attr"`
+		Lang      string `xml:"lang,attr"`
+	} `xml:"titles>title"`
+	Publisher       string `xml:"publisher"`
+	PublicationYear string `xml:"publicationYear"`
+	ResourceType    struct {
+		Text                string `xml:",chardata"`
+		ResourceTypeGeneral string `xml:"resourceTypeGeneral,attr"`
+	} `xml:"resourceType"`
+	Subjects []struct {
+		Text          string `xml:",chardata"`
+		SubjectScheme string `xml:"subjectScheme,attr"`
+	} `xml:"subjects>subject"`
+	Dates []struct {
+		Text     string `xml:",chardata"`
+		DateType string `xml:"dateType,attr"`
+	} `xml:"dates>date"`
+	Language           string `xml:"language"`
+	RelatedIdentifiers []struct {
+		Text                  string `xml:",chardata"`
+		RelatedIdentifierType string `xml:"relatedIdentifierType,attr"`
+		RelationType          string `xml:"relationType,attr"`
+	} `xml:"relatedIdentifiers>relatedIdentifier"`
+	RightsList []struct {
+		Text             string `xml:",chardata"`
+		RightsURI        string `xml:"rightsURI,attr"`
+		RightsIdentifier string `xml:"rightsIdentifier,attr"`
+	} `xml:"rightsList>rights"`
+	Descriptions []struct {
+		Text            string `xml:",chardata"`
+		DescriptionType string `xml:"descriptionType,attr"`
+	} `xml:"descriptions>description"`
+	Version string `xml:"version"`
+	// SourceID of converted records, refs. Document.SourceID.
+	SourceID string `xml:"-"`
+}
+
+// Attributes returns the metadata in the structure of the REST API.
+func (r *Resource) Attributes() Attributes {
+	a := Attributes{
+		Language: strings.TrimSpace(r.Language),
+		Types: Types{
+			ResourceTypeGeneral: r.ResourceType.ResourceTypeGeneral,
+			ResourceType:        strings.TrimSpace(r.ResourceType.Text),
+		},
+		Version:  strings.TrimSpace(r.Version),
+		SourceID: r.SourceID,
+	}
+	if r.Identifier.IdentifierType == "DOI" {
+		a.DOI = strings.TrimSpace(r.Identifier.Text)
+	}
+	a.Publisher, _ = json.Marshal(strings.TrimSpace(r.Publisher))
+	a.PublicationYear, _ = json.Marshal(strings.TrimSpace(r.PublicationYear))
+	for _, c := range r.Creators {
+		creator := Creator{
+			Name:       strings.TrimSpace(c.CreatorName.Text),
+			NameType:   c.CreatorName.NameType,
+			GivenName:  strings.TrimSpace(c.GivenName),
+			FamilyName: strings.TrimSpace(c.FamilyName),
+		}
+		for _, id := range c.NameIdentifier {
+			creator.NameIdentifiers = append(creator.NameIdentifiers, NameIdentifier{
+				NameIdentifier:       strings.TrimSpace(id.Text),
+				NameIdentifierScheme: id.NameIdentifierScheme,
+				SchemeURI:            id.SchemeURI,
+			})
+		}
+		a.Creators = append(a.Creators, creator)
+	}
+	for _, t := range r.Titles {
+		a.Titles = append(a.Titles, Title{Title: t.Text, TitleType: t.TitleType, Lang: t.Lang})
+	}
+	for _, s := range r.Subjects {
+		a.Subjects = append(a.Subjects, Subject{Subject: s.Text, SubjectScheme: s.SubjectScheme})
+	}
+	for _, d := range r.Dates {
+		a.Dates = append(a.Dates, Date{Date: strings.TrimSpace(d.Text), DateType: d.DateType})
+	}
+	for _, v := range r.RelatedIdentifiers {
+		a.RelatedIdentifiers = append(a.RelatedIdentifiers, RelatedIdentifier{
+			RelatedIdentifier:     v.Text,
+			RelatedIdentifierType: v.RelatedIdentifierType,
+			RelationType:          v.RelationType,
+		})
+	}
+	for _, v := range r.RightsList {
+		a.RightsList = append(a.RightsList, Rights{
+			Rights:           v.Text,
+			RightsURI:        v.RightsURI,
+			RightsIdentifier: v.RightsIdentifier,
+		})
+	}
+	for _, d := range r.Descriptions {
+		a.Descriptions = append(a.Descriptions, Description{
+			Description:     d.Text,
+			DescriptionType: d.DescriptionType,
+		})
+	}
+	return a
+}
+
+// ToIntermediateSchema converts a XML record.
+func (r *Resource) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	a := r.Attributes()
+	return a.ToIntermediateSchema()
+}
@@ -225,6 +225,16 @@ func (doc Document) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 		output.URL = append(output.URL, "https://doaj.org/article/"+doc.ID)
 	}
 
+	// License type, e.g. "CC BY", or URL, if there is no type.
+	for _, l := range doc.BibJSON.Journal.License {
+		switch {
+		case l.Type != "":
+			output.License = append(output.License, l.Type)
+		case l.URL != "":
+			output.License = append(output.License, l.URL)
+		}
+	}
+
 	output.StartPage = doc.BibJSON.StartPage
 	output.EndPage = doc.BibJSON.EndPage
 
@@ -155,6 +155,16 @@ func (doc ArticleV1) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 		output.URL = append(output.URL, "https://doaj.org/article/"+doc.Id)
 	}
 
+	// License type, e.g. "CC BY", or URL, if there is no type.
+	for _, l := range doc.Bibjson.Journal.License {
+		switch {
+		case l.Type != "":
+			output.License = append(output.License, l.Type)
+		case l.Url != "":
+			output.License = append(output.License, l.Url)
+		}
+	}
+
 	output.StartPage = doc.Bibjson.StartPage
 	output.EndPage = doc.Bibjson.EndPage
 
@@ -67,6 +67,10 @@ type Author struct {
 	// Constraint Definition of KEV Metadata Format for "book", Excerpt,
 	// https://groups.niso.org/apps/group_public/download.php/14833/z39_88_2004_r2010.pdf#page=55).
 	Corporate string `json:"rft.aucorp,omitempty"`
+
+	// ROR lists the institutions an author is affiliated with, as ROR URL,
+	// e.g. "https://ror.org/03s7gtk40".
+	ROR []string `json:"x.ror,omitempty"`
 }
 
 // String returns a formatted author string.
@@ -164,6 +168,12 @@ type IntermediateSchema struct {
 	// OpenAccess, refs. #8986, prototype
 	OpenAccess bool     `json:"x.oa,omitempty"`
 	License    []string `json:"x.license,omitempty"`
+	// OpenAccessEvidence records what x.oa is based on, e.g. "doaj:1234-5678".
+	OpenAccessEvidence string `json:"x.oa_evidence,omitempty"`
+
+	// ZDBID identifies a journal in the Zeitschriftendatenbank, e.g.
+	// "1459367-1". Holdings entries with a ZDB-ID are matched by it first.
+	ZDBID string `json:"x.zdbid,omitempty"`
 
 	// Footnote, via solr schema, refs #13653
 	Footnotes []string `json:"x.footnotes,omitempty"`
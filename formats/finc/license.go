@@ -0,0 +1,89 @@
+package finc
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/miku/span/assetutil"
+)
+
+// LicenseMap maps lowercase raw license values, like DOAJ license types,
+// SSOAR rights statements or publisher license URLs, to license identifiers.
+var LicenseMap = assetutil.MustLoadStringMap("assets/finc/licenses.json")
+
+var (
+	// licenseURLPattern matches Creative Commons license URLs, e.g.
+	// https://creativecommons.org/licenses/by-nc/4.0/deed.de
+	licenseURLPattern = regexp.MustCompile(`creativecommons\.org/licenses/([a-z-]+)/([0-9]\.[0-9])`)
+	// publicDomainURLPattern matches CC0 and the public domain mark.
+	publicDomainURLPattern = regexp.MustCompile(`creativecommons\.org/publicdomain/(zero|mark)/([0-9]\.[0-9])`)
+	// licenseNamePattern matches Creative Commons license names, e.g. "CC
+	// BY-NC-ND 4.0", "cc_by_4.0" or "CC-BY".
+	licenseNamePattern = regexp.MustCompile(`^(?:cc|creative commons)[ _-]*(by(?:[ _-]+(?:nc|nd|sa))*)(?:[ _-]+v?([0-9]\.[0-9]))?\b`)
+	// licenseZeroPattern matches names of CC0.
+	licenseZeroPattern = regexp.MustCompile(`^(?:cc|creative commons)[ _-]*(?:0|zero)\b`)
+)
+
+// licenseElements returns the license elements, like "by-nc-nd" in
+// canonical order, so "by_nd_nc" and "BY-NC-ND" result in the same value.
+func licenseElements(s string) string {
+	parts := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool {
+		return r == '-' || r == '_' || r == ' '
+	})
+	var result []string
+	for _, p := range []string{"BY", "NC", "ND", "SA"} {
+		if slices.Contains(parts, p) {
+			result = append(result, p)
+		}
+	}
+	return strings.Join(result, "-")
+}
+
+// NormalizeLicense returns a SPDX-like identifier for a raw license value,
+// e.g. "CC-BY-NC-ND-3.0" for
+// "http://creativecommons.org/licenses/by-nc-nd/3.0/". Creative Commons
+// licenses without a version, e.g. "CC BY", result in an identifier without
+// a version, e.g. "CC-BY". Other licenses are looked up in LicenseMap. An
+// empty string is returned, if the value is not recognized.
+func NormalizeLicense(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return ""
+	}
+	if v := LicenseMap.Lookup(s, ""); v != "" {
+		return v
+	}
+	if m := licenseURLPattern.FindStringSubmatch(s); m != nil {
+		return "CC-" + licenseElements(m[1]) + "-" + m[2]
+	}
+	if m := publicDomainURLPattern.FindStringSubmatch(s); m != nil {
+		if m[1] == "zero" {
+			return "CC0-" + m[2]
+		}
+		return "PDM-" + m[2]
+	}
+	if licenseZeroPattern.MatchString(s) {
+		return "CC0-1.0"
+	}
+	if m := licenseNamePattern.FindStringSubmatch(s); m != nil {
+		if m[2] == "" {
+			return "CC-" + licenseElements(m[1])
+		}
+		return "CC-" + licenseElements(m[1]) + "-" + m[2]
+	}
+	return ""
+}
+
+// LicenseIDs returns the sorted, deduplicated license identifiers of all
+// recognized license values of a record.
+func (is *IntermediateSchema) LicenseIDs() []string {
+	var ids []string
+	for _, l := range is.License {
+		if id := NormalizeLicense(l); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,48 @@
+package finc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeLicense(t *testing.T) {
+	var tests = []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"http://creativecommons.org/licenses/by/4.0/", "CC-BY-4.0"},
+		{"https://creativecommons.org/licenses/by-nc-nd/3.0/de/deed.de", "CC-BY-NC-ND-3.0"},
+		{"http://creativecommons.org/publicdomain/zero/1.0/", "CC0-1.0"},
+		{"https://creativecommons.org/publicdomain/mark/1.0/", "PDM-1.0"},
+		{"CC BY", "CC-BY"},
+		{"CC BY-NC-SA", "CC-BY-NC-SA"},
+		{"cc_by_nd_nc 4.0", "CC-BY-NC-ND-4.0"},
+		{"CC-BY-SA-4.0", "CC-BY-SA-4.0"},
+		{"CC0", "CC0-1.0"},
+		{"Creative Commons Attribution 4.0 International", "CC-BY-4.0"},
+		{"Creative Commons - Namensnennung, Nicht kommerz., Keine Bearbeitung", "CC-BY-NC-ND"},
+		{"http://doi.wiley.com/10.1002/tdm_license_1.1", "WILEY-TDM-1.1"},
+		{"All rights reserved", ""},
+		{"ccby", "CC-BY"},
+		{"accbx", ""},
+	}
+	for _, test := range tests {
+		if got := NormalizeLicense(test.s); got != test.want {
+			t.Errorf("NormalizeLicense(%q) got %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestLicenseIDs(t *testing.T) {
+	is := IntermediateSchema{License: []string{
+		"http://creativecommons.org/licenses/by/4.0/",
+		"CC BY 4.0",
+		"unknown",
+		"CC BY-NC",
+	}}
+	want := []string{"CC-BY-4.0", "CC-BY-NC"}
+	if got := is.LicenseIDs(); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -40,6 +40,7 @@ type Solr5Vufind3 struct {
 	ISBN                 []string `json:"isbn,omitempty"`
 	ISBNStrMv            []string `json:"isbn_str_mv,omitempty"` // refs. #21393
 	Languages            []string `json:"language,omitempty"`
+	Licenses             []string `json:"license_str_mv,omitempty"`
 	MegaCollections      []string `json:"mega_collection,omitempty"`
 	MatchStr             string   `json:"match_str"`    // do not omit, refs. #21403#note-15
 	MatchStrMv           []string `json:"match_str_mv"` // do not omit, refs. #21403#note-15
@@ -266,6 +267,9 @@ func (s *Solr5Vufind3) convert(is IntermediateSchema, withFullrecord bool) error
 		s.FacetAvail = append(s.FacetAvail, "Free")
 	}
 
+	// Normalized license identifiers, e.g. CC-BY-4.0, for faceting.
+	s.Licenses = is.LicenseIDs()
+
 	// refs #11478
 	s.Physical = []string{is.Pages}
 
@@ -32,6 +32,7 @@ import (
 	"github.com/miku/span"
 	"github.com/miku/span/container"
 	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
 	"github.com/miku/span/strutil"
 )
 
@@ -65,6 +66,7 @@ type Document struct {
 	RawDate     string   `xml:"Date"`         // 20050101, 20050501
 	Issue       string   `xml:"Issue"`        // 1, 2
 	ISSN        string   `xml:"ISSN"`         // 1861-1303
+	ZDBID       string   `xml:"ZDB-ID"`       // 2107232-4
 	ISBN        string   `xml:"ISBN"`         // n.n.
 	Subtitle    string   `xml:"Subtitle"`     // n.n.
 	SeriesTitle string   `xml:"Series-Title"` // n.n.
@@ -261,6 +263,7 @@ func (doc Document) ToIntermediateSchema() (output *finc.IntermediateSchema, err
 	// TODO(miku): Find DB names where this is relevant.
 	output.JournalTitle = strings.Replace(strings.TrimSpace(doc.PublicationTitle), "\n", " ", -1)
 	output.ISSN = doc.ISSNList()
+	output.ZDBID = licensing.NormalizeZDBID(doc.ZDBID)
 	if !isNomenNescio(doc.Issue) {
 		output.Issue = strings.TrimSpace(doc.Issue)
 	}
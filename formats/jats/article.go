@@ -13,6 +13,7 @@ import (
 	"github.com/miku/span"
 	"github.com/miku/span/container"
 	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
 	"golang.org/x/text/language"
 )
 
@@ -76,11 +77,10 @@ type Article struct {
 	Front   struct {
 		XMLName xml.Name `xml:"front"`
 		Journal struct {
-			ID struct {
-				XMLName xml.Name `xml:"journal-id"`
-				Type    string   `xml:"journal-id-type,attr"`
-				Value   string   `xml:",chardata"`
-			}
+			ID []struct {
+				Type  string `xml:"journal-id-type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"journal-id"`
 			ISSN []struct {
 				Type  string `xml:"pub-type,attr"`
 				Value string `xml:",chardata"`
@@ -336,6 +336,20 @@ func (article *Article) JournalTitle() string {
 	return article.Front.Journal.AbbreviatedTitle.Title
 }
 
+// ZDBID returns the normalized ZDB-ID from a journal-id with type "zdb" or
+// "zdb-id", if there is any.
+func (article *Article) ZDBID() string {
+	for _, id := range article.Front.Journal.ID {
+		switch strings.ToLower(id.Type) {
+		case "zdb", "zdb-id", "zdbid":
+			if v := licensing.NormalizeZDBID(id.Value); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
 // ISSN returns a list of ISSNs associated with this article.
 func (article *Article) ISSN() (issns []string) {
 	for _, issn := range article.Front.Journal.ISSN {
@@ -493,6 +507,7 @@ func (article *Article) ToIntermediateSchema() (*finc.IntermediateSchema, error)
 	output.ISSN = article.ISSN()
 	output.Issue = article.Front.Article.Issue.Value
 	output.JournalTitle = article.JournalTitle()
+	output.ZDBID = article.ZDBID()
 	output.Languages = article.Languages()
 	output.Publishers = append(output.Publishers, article.Front.Journal.Publisher.Name.Value)
 	output.Subjects = article.Subjects()
@@ -0,0 +1,117 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	recordTerminator = 0x1d
+	subfieldDelim    = 0x1f
+	leaderLength     = 24
+	directoryEntry   = 12
+)
+
+// ErrInvalidRecord is returned for records, that do not follow ISO 2709.
+var ErrInvalidRecord = errors.New("marc: invalid record")
+
+// Reader reads binary MARC21 records (ISO 2709) from a stream. Values are
+// expected in UTF-8, invalid bytes, e.g. from MARC-8 encoded records, are
+// replaced.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a reader for binary MARC21 records.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Read returns the next record or io.EOF, if there are no more records.
+func (r *Reader) Read() (*Marc21Record, error) {
+	b, err := r.r.ReadBytes(recordTerminator)
+	if err == io.EOF {
+		if len(bytes.TrimSpace(b)) == 0 {
+			return nil, io.EOF
+		}
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Line breaks between records are common in the wild.
+	b = bytes.TrimLeft(b, "\r\n")
+	return decodeRecord(b)
+}
+
+// parseDigits parses a number in the leader or directory, which consists of
+// ASCII digits only, so signs or spaces are rejected.
+func parseDigits(b []byte) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	var n int
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// decodeRecord parses a single record, including the record terminator.
+func decodeRecord(b []byte) (*Marc21Record, error) {
+	if len(b) < leaderLength+1 {
+		return nil, fmt.Errorf("%w: record too short", ErrInvalidRecord)
+	}
+	base, ok := parseDigits(b[12:17])
+	if !ok || base <= leaderLength || base > len(b) {
+		return nil, fmt.Errorf("%w: base address: %q", ErrInvalidRecord, b[12:17])
+	}
+	record := &Marc21Record{Leader: string(b[:leaderLength])}
+	directory := b[leaderLength : base-1]
+	for len(directory) >= directoryEntry {
+		var (
+			entry       = directory[:directoryEntry]
+			tag         = string(entry[:3])
+			length, okL = parseDigits(entry[3:7])
+			start, okS  = parseDigits(entry[7:12])
+		)
+		directory = directory[directoryEntry:]
+		if !okL || !okS {
+			return nil, fmt.Errorf("%w: field %s: directory entry: %q", ErrInvalidRecord, tag, entry)
+		}
+		if length == 0 || base+start+length > len(b) {
+			return nil, fmt.Errorf("%w: field %s out of range", ErrInvalidRecord, tag)
+		}
+		data := bytes.TrimRight(b[base+start:base+start+length], "\x1e\x1d")
+		if tag < "010" {
+			record.ControlFields = append(record.ControlFields, ControlField{
+				Tag:   tag,
+				Value: strings.ToValidUTF8(string(data), "�"),
+			})
+			continue
+		}
+		field := DataField{Tag: tag, Ind1: " ", Ind2: " "}
+		if len(data) >= 2 {
+			field.Ind1, field.Ind2 = string(data[0]), string(data[1])
+			data = data[2:]
+		}
+		for _, sf := range bytes.Split(data, []byte{subfieldDelim}) {
+			if len(sf) == 0 {
+				continue
+			}
+			field.Subfields = append(field.Subfields, Subfield{
+				Code:  string(sf[0]),
+				Value: strings.ToValidUTF8(string(sf[1:]), "�"),
+			})
+		}
+		record.DataFields = append(record.DataFields, field)
+	}
+	return record, nil
+}
@@ -0,0 +1,311 @@
+package marc
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+var (
+	// yearPattern matches a plausible publication year, e.g. in "c2019".
+	yearPattern = regexp.MustCompile(`\b(1[5-9][0-9]{2}|20[0-9]{2})\b`)
+	// volumePattern, issuePattern and pagesPattern find parts of a 773.g
+	// value, like "Vol. 12, no. 3 (2019), p. 45-67" or "Bd. 12 (2019), Heft
+	// 3, S. 45-67".
+	volumePattern = regexp.MustCompile(`(?i)\b(?:vol|volume|bd|band|jg|jahrgang)\.?\s*([0-9]+)`)
+	issuePattern  = regexp.MustCompile(`(?i)\b(?:no|nr|issue|heft|h)\.?\s*([0-9]+)`)
+	pagesPattern  = regexp.MustCompile(`(?i)\b(?:pp|p|s|pages|seiten)\.?\s*([0-9]+)\s*[-–]\s*([0-9]+)`)
+)
+
+// ControlField is a control field, e.g. 001 or 008.
+type ControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Subfield of a data field.
+type Subfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// DataField is a data field with indicators and subfields.
+type DataField struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+// Marc21Record is a generic MARC21 bibliographic record, read from MARCXML
+// or from binary ISO 2709, refs. Reader. Unlike Record, it is not tied to a
+// single source, fields are converted according to a Mapping.
+type Marc21Record struct {
+	XMLName       xml.Name       `xml:"record"`
+	Leader        string         `xml:"leader"`
+	ControlFields []ControlField `xml:"controlfield"`
+	DataFields    []DataField    `xml:"datafield"`
+	// Mapping to use for conversion, DefaultMapping if nil.
+	Mapping *Mapping `xml:"-"`
+}
+
+// Values returns all values for a field spec. A spec is a control field tag,
+// like "001", a control field tag with a position or range, like "008/35-37",
+// a data field tag with subfield code, like "245.a", or a data field tag
+// alone, like "245", in which case all subfields are joined with a space.
+func (r *Marc21Record) Values(spec string) (result []string) {
+	if tag, pos, ok := strings.Cut(spec, "/"); ok {
+		from, to, _ := strings.Cut(pos, "-")
+		i, err := strconv.Atoi(from)
+		if err != nil {
+			return nil
+		}
+		j := i
+		if to != "" {
+			if j, err = strconv.Atoi(to); err != nil || j < i {
+				return nil
+			}
+		}
+		for _, f := range r.ControlFields {
+			if f.Tag == tag && len(f.Value) > j {
+				result = append(result, f.Value[i:j+1])
+			}
+		}
+		return result
+	}
+	tag, code, hasCode := strings.Cut(spec, ".")
+	if tag < "010" {
+		for _, f := range r.ControlFields {
+			if f.Tag == tag {
+				result = append(result, f.Value)
+			}
+		}
+		return result
+	}
+	for _, f := range r.DataFields {
+		if f.Tag != tag {
+			continue
+		}
+		var values []string
+		for _, sf := range f.Subfields {
+			if !hasCode || sf.Code == code {
+				values = append(values, strings.TrimSpace(sf.Value))
+			}
+		}
+		if hasCode {
+			result = append(result, values...)
+		} else if len(values) > 0 {
+			result = append(result, strings.Join(values, " "))
+		}
+	}
+	return result
+}
+
+// First returns the first non-empty value of the first spec, that has one.
+func (r *Marc21Record) First(specs ...string) string {
+	for _, spec := range specs {
+		for _, v := range r.Values(spec) {
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// All returns all non-empty values of all specs, without duplicates.
+func (r *Marc21Record) All(specs ...string) (result []string) {
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		for _, v := range r.Values(spec) {
+			if v = strings.TrimSpace(v); v != "" && !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// Mapping configures, which fields are used for the intermediate schema.
+// Each value is a list of field specs, refs. Marc21Record.Values. A mapping
+// can be read from JSON with ReadMapping.
+type Mapping struct {
+	SourceID        string   `json:"source_id"`
+	MegaCollections []string `json:"collections"`
+	Format          string   `json:"format"` // Overrides the format derived from the leader.
+	ID              []string `json:"id"`
+	Title           []string `json:"title"`
+	Subtitle        []string `json:"subtitle"`
+	Authors         []string `json:"authors"`
+	ISBN            []string `json:"isbn"`
+	ISSN            []string `json:"issn"`
+	DOI             []string `json:"doi"`
+	Container       []string `json:"container"`
+	ContainerISSN   []string `json:"container_issn"`
+	ContainerISBN   []string `json:"container_isbn"`
+	ContainerParts  []string `json:"container_parts"` // Volume, issue and pages.
+	Publishers      []string `json:"publishers"`
+	Places          []string `json:"places"`
+	Date            []string `json:"date"`
+	Languages       []string `json:"languages"`
+	Subjects        []string `json:"subjects"`
+	Abstract        []string `json:"abstract"`
+	Series          []string `json:"series"`
+	URL             []string `json:"url"`
+}
+
+// DefaultMapping follows common MARC21 practice. Source id and collections
+// must be configured per delivery.
+var DefaultMapping = Mapping{
+	ID:             []string{"001"},
+	Title:          []string{"245.a"},
+	Subtitle:       []string{"245.b"},
+	Authors:        []string{"100.a", "110.a", "700.a", "710.a"},
+	ISBN:           []string{"020.a"},
+	ISSN:           []string{"022.a"},
+	DOI:            []string{"024.a", "856.u"},
+	Container:      []string{"773.t"},
+	ContainerISSN:  []string{"773.x"},
+	ContainerISBN:  []string{"773.z"},
+	ContainerParts: []string{"773.g"},
+	Publishers:     []string{"264.b", "260.b"},
+	Places:         []string{"264.a", "260.a"},
+	Date:           []string{"264.c", "260.c", "008/07-10"},
+	Languages:      []string{"041.a", "008/35-37"},
+	Subjects:       []string{"650.a", "653.a"},
+	Abstract:       []string{"520.a"},
+	Series:         []string{"490.a"},
+	URL:            []string{"856.u"},
+}
+
+// trimISBD removes trailing ISBD punctuation, e.g. " /" or " :".
+func trimISBD(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,="))
+}
+
+// ToIntermediateSchema converts a record according to its mapping. The
+// bibliographic level in leader position 7 decides between book (m), book
+// chapter (a), serial (s) and article (b and everything else).
+func (r *Marc21Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	m := r.Mapping
+	if m == nil {
+		m = &DefaultMapping
+	}
+	if m.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	output.RecordID = r.First(m.ID...)
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "marc: missing record id"}
+	}
+	output.SourceID = m.SourceID
+	output.ID = span.GenFincID(output.SourceID, output.RecordID)
+	output.MegaCollections = m.MegaCollections
+
+	title := trimISBD(r.First(m.Title...))
+	if title == "" {
+		return output, span.Skip{Reason: "marc: missing title: " + output.RecordID}
+	}
+	if subtitle := trimISBD(r.First(m.Subtitle...)); subtitle != "" {
+		title = title + ": " + subtitle
+	}
+	var level byte
+	if len(r.Leader) > 7 {
+		level = r.Leader[7]
+	}
+	switch level {
+	case 'm':
+		output.BookTitle = title
+		output.Format = "eBook"
+		output.Genre = "book"
+		output.RefType = "EBOOK"
+	case 'a':
+		output.ArticleTitle = title
+		output.BookTitle = trimISBD(r.First(m.Container...))
+		output.Format = "ElectronicBookPart"
+		output.Genre = "bookitem"
+		output.RefType = "CHAP"
+	case 's':
+		// A serial as a whole has no schema genre, refs. crossref "journal".
+		output.ArticleTitle = title
+		output.JournalTitle = title
+		output.Format = "ElectronicJournal"
+		output.Genre = "unknown"
+		output.RefType = "JOUR"
+	default:
+		output.ArticleTitle = title
+		output.JournalTitle = trimISBD(r.First(m.Container...))
+		output.Format = "ElectronicArticle"
+		output.Genre = "article"
+		output.RefType = "EJOUR"
+	}
+	if m.Format != "" {
+		output.Format = m.Format
+	}
+	for _, name := range r.All(m.Authors...) {
+		output.Authors = append(output.Authors, finc.Author{Name: trimISBD(name)})
+	}
+	output.ISBN = r.All(slices.Concat(m.ISBN, m.ContainerISBN)...)
+	output.ISSN = r.All(slices.Concat(m.ISSN, m.ContainerISSN)...)
+	for _, v := range r.All(m.DOI...) {
+		if doi := span.FindDOI(v); doi != "" {
+			output.DOI = doi
+			break
+		}
+	}
+	if parts := r.First(m.ContainerParts...); parts != "" {
+		if s := volumePattern.FindStringSubmatch(parts); s != nil {
+			output.Volume = s[1]
+		}
+		if s := issuePattern.FindStringSubmatch(parts); s != nil {
+			output.Issue = s[1]
+		}
+		if s := pagesPattern.FindStringSubmatch(parts); s != nil {
+			output.StartPage, output.EndPage = s[1], s[2]
+			output.Pages = s[1] + "-" + s[2]
+		}
+	}
+	for _, v := range r.All(m.Publishers...) {
+		output.Publishers = append(output.Publishers, trimISBD(v))
+	}
+	for _, v := range r.All(m.Places...) {
+		output.Places = append(output.Places, trimISBD(v))
+	}
+	for _, v := range r.All(m.Date...) {
+		if year := yearPattern.FindString(v); year != "" {
+			output.RawDate = year + "-01-01"
+			output.Date, _ = time.Parse("2006-01-02", output.RawDate)
+			break
+		}
+	}
+	for _, v := range r.All(m.Languages...) {
+		if lang := span.LanguageCode(v); lang != "" && !slices.Contains(output.Languages, lang) {
+			output.Languages = append(output.Languages, lang)
+		}
+	}
+	output.Subjects = r.All(m.Subjects...)
+	output.Abstract = strings.Join(r.All(m.Abstract...), "\n")
+	output.Series = r.First(m.Series...)
+	output.URL = r.All(m.URL...)
+	return output, nil
+}
+
+// ReadMapping reads a JSON mapping. Keys not in the JSON keep their value
+// from DefaultMapping.
+func ReadMapping(r io.Reader) (*Mapping, error) {
+	m := DefaultMapping
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
@@ -0,0 +1,213 @@
+package marc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/xmlstream"
+)
+
+// encode returns a record in ISO 2709.
+func encode(r *Marc21Record) string {
+	var dir, data strings.Builder
+	add := func(tag, value string) {
+		fmt.Fprintf(&dir, "%s%04d%05d", tag, len(value)+1, data.Len())
+		data.WriteString(value + "\x1e")
+	}
+	for _, f := range r.ControlFields {
+		add(f.Tag, f.Value)
+	}
+	for _, f := range r.DataFields {
+		var sb strings.Builder
+		sb.WriteString(f.Ind1 + f.Ind2)
+		for _, sf := range f.Subfields {
+			sb.WriteString("\x1f" + sf.Code + sf.Value)
+		}
+		add(f.Tag, sb.String())
+	}
+	base := 24 + dir.Len() + 1
+	length := base + data.Len() + 1
+	leader := fmt.Sprintf("%05d%s%05d%s", length, r.Leader[5:12], base, r.Leader[17:])
+	return leader + dir.String() + "\x1e" + data.String() + "\x1d"
+}
+
+func readMarcXML(t *testing.T) (records []*Marc21Record) {
+	f, err := os.Open("testdata/sample.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	scanner := xmlstream.NewScanner(f, new(Marc21Record))
+	for scanner.Scan() {
+		records = append(records, scanner.Element().(*Marc21Record))
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestMarc21RecordValues(t *testing.T) {
+	r := readMarcXML(t)[0]
+	var cases = []struct {
+		spec   string
+		result []string
+	}{
+		{"001", []string{"123"}},
+		{"008/07-10", []string{"2019"}},
+		{"008/35-37", []string{"ger"}},
+		{"245.a", []string{"Über Beispiele :"}},
+		{"245", []string{"Über Beispiele : eine Studie / Anna Meier"}},
+		{"100.a", []string{"Meier, Anna,"}},
+		{"999.a", nil},
+		{"008/70-72", nil},
+		{"008/10-07", nil},
+		{"008/x", nil},
+	}
+	for _, c := range cases {
+		if got := r.Values(c.spec); !reflect.DeepEqual(got, c.result) {
+			t.Errorf("Values(%q): got %q, want %q", c.spec, got, c.result)
+		}
+	}
+}
+
+func TestTrimISBD(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"Über Beispiele :", "Über Beispiele"},
+		{"Meier, Anna,", "Meier, Anna"},
+		{"eine Studie /", "eine Studie"},
+		{"A book.", "A book."},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := trimISBD(c.s); got != c.result {
+			t.Errorf("trimISBD(%q): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+// TestMarc21RecordToIntermediateSchema converts testdata/sample.xml, an
+// article with host item entry and a book.
+func TestMarc21RecordToIntermediateSchema(t *testing.T) {
+	mapping := DefaultMapping
+	mapping.SourceID = "999"
+	var result []*finc.IntermediateSchema
+	for _, r := range readMarcXML(t) {
+		if _, err := r.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Errorf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		r.Mapping = &mapping
+		output, err := r.ToIntermediateSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d records, want 2", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-MTIz"},
+		{0, "Genre", result[0].Genre, "article"},
+		{0, "Format", result[0].Format, "ElectronicArticle"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "Über Beispiele: eine Studie"},
+		{0, "JournalTitle", result[0].JournalTitle, "Zeitschrift für Beispiele"},
+		{0, "ISSN", result[0].ISSN, []string{"1234-5678"}},
+		{0, "Volume", result[0].Volume, "12"},
+		{0, "Issue", result[0].Issue, "3"},
+		{0, "Pages", result[0].Pages, "45-67"},
+		{0, "RawDate", result[0].RawDate, "2019-01-01"},
+		{0, "Authors", result[0].Authors, []finc.Author{{Name: "Meier, Anna"}, {Name: "Schulz, Bernd"}}},
+		{0, "DOI", result[0].DOI, "10.1000/xyz.123"},
+		{0, "Languages", result[0].Languages, []string{"deu"}},
+		{1, "ID", result[1].ID, "ai-999-NDU2"},
+		{1, "Genre", result[1].Genre, "book"},
+		{1, "BookTitle", result[1].BookTitle, "A book."},
+		{1, "ISBN", result[1].ISBN, []string{"9783161484100"}},
+		{1, "Publishers", result[1].Publishers, []string{"Example Press"}},
+		{1, "Places", result[1].Places, []string{"Berlin"}},
+		{1, "Languages", result[1].Languages, []string{"eng"}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
+
+func TestMarc21RecordSerial(t *testing.T) {
+	mapping := DefaultMapping
+	mapping.SourceID = "999"
+	r := &Marc21Record{
+		Leader:        "00000cas a2200000 a 4500",
+		ControlFields: []ControlField{{Tag: "001", Value: "456"}},
+		DataFields: []DataField{
+			{Tag: "245", Subfields: []Subfield{{Code: "a", Value: "Beispielzeitschrift /"}}},
+		},
+		Mapping: &mapping,
+	}
+	output, err := r.ToIntermediateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Genre != "unknown" {
+		t.Errorf("got genre %q, want %q", output.Genre, "unknown")
+	}
+	if output.ArticleTitle != "Beispielzeitschrift" || output.JournalTitle != "Beispielzeitschrift" {
+		t.Errorf("got titles %q and %q", output.ArticleTitle, output.JournalTitle)
+	}
+}
+
+func TestReader(t *testing.T) {
+	records := readMarcXML(t)
+	var sb strings.Builder
+	for _, r := range records {
+		sb.WriteString(encode(r) + "\n")
+	}
+	reader := NewReader(strings.NewReader(sb.String()))
+	for i := 0; ; i++ {
+		r, err := reader.Read()
+		if err == io.EOF {
+			if i != len(records) {
+				t.Fatalf("got %d records, want %d", i, len(records))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r.ControlFields, records[i].ControlFields) {
+			t.Errorf("got %v, want %v", r.ControlFields, records[i].ControlFields)
+		}
+		if !reflect.DeepEqual(r.DataFields, records[i].DataFields) {
+			t.Errorf("got %v, want %v", r.DataFields, records[i].DataFields)
+		}
+	}
+	valid := encode(records[1])
+	var corrupt = []string{
+		"00042nam a22xxxxx",
+		valid[:31] + "-0001" + valid[36:], // negative start
+		valid[:27] + "-001" + valid[31:],  // negative length
+		valid[:12] + " 0100" + valid[17:], // base address with space
+	}
+	for _, s := range corrupt {
+		if _, err := NewReader(strings.NewReader(s)).Read(); !errors.Is(err, ErrInvalidRecord) {
+			t.Errorf("got %v, want %v", err, ErrInvalidRecord)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// Package openalex converts works from the OpenAlex snapshot
+// (https://docs.openalex.org/download-all-data/openalex-snapshot), one JSON
+// document per line, to intermediate schema. OpenAlex complements crossref
+// with abstracts, open access status, affiliations and subjects.
+//
+//	$ zcat works/updated_date=*/part_*.gz | span-import -i openalex -sid 999
+package openalex
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/crossref"
+	"github.com/miku/span/formats/finc"
+)
+
+var (
+	// MinConceptScore is the score a concept needs to become a subject.
+	// OpenAlex itself considers concepts below 0.3 as not assigned.
+	MinConceptScore = 0.3
+
+	// crossrefTypes maps OpenAlex work types to crossref types, for works
+	// without type_crossref.
+	crossrefTypes = map[string]string{
+		"article":      "journal-article",
+		"book":         "book",
+		"book-chapter": "book-chapter",
+		"dataset":      "dataset",
+		"dissertation": "dissertation",
+		"editorial":    "journal-article",
+		"letter":       "journal-article",
+		"preprint":     "posted-content",
+		"report":       "report",
+		"review":       "journal-article",
+		"standard":     "standard",
+	}
+)
+
+// Source is a journal, repository or other venue.
+type Source struct {
+	ID                   string   `json:"id"`
+	DisplayName          string   `json:"display_name"`
+	ISSNL                string   `json:"issn_l"`
+	ISSN                 []string `json:"issn"`
+	HostOrganizationName string   `json:"host_organization_name"`
+	Type                 string   `json:"type"`
+}
+
+// Location is a place, where a work is available.
+type Location struct {
+	IsOA           bool    `json:"is_oa"`
+	LandingPageURL string  `json:"landing_page_url"`
+	PDFURL         string  `json:"pdf_url"`
+	Source         *Source `json:"source"`
+	License        string  `json:"license"`
+	Version        string  `json:"version"`
+}
+
+// Authorship is an author of a work with affiliations.
+type Authorship struct {
+	AuthorPosition string `json:"author_position"`
+	Author         struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+		ORCID       string `json:"orcid"`
+	} `json:"author"`
+	Institutions []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+		ROR         string `json:"ror"`
+	} `json:"institutions"`
+	RawAuthorName string `json:"raw_author_name"`
+}
+
+// Work is a single OpenAlex work, only fields needed for conversion.
+type Work struct {
+	ID              string    `json:"id"`
+	DOI             string    `json:"doi"`
+	Title           string    `json:"title"`
+	DisplayName     string    `json:"display_name"`
+	PublicationYear int       `json:"publication_year"`
+	PublicationDate string    `json:"publication_date"`
+	Language        string    `json:"language"`
+	Type            string    `json:"type"`
+	TypeCrossref    string    `json:"type_crossref"`
+	PrimaryLocation *Location `json:"primary_location"`
+	BestOALocation  *Location `json:"best_oa_location"`
+	OpenAccess      struct {
+		IsOA     bool   `json:"is_oa"`
+		OAStatus string `json:"oa_status"`
+		OAURL    string `json:"oa_url"`
+	} `json:"open_access"`
+	Authorships []Authorship `json:"authorships"`
+	Biblio      struct {
+		Volume    string `json:"volume"`
+		Issue     string `json:"issue"`
+		FirstPage string `json:"first_page"`
+		LastPage  string `json:"last_page"`
+	} `json:"biblio"`
+	Concepts []struct {
+		DisplayName string  `json:"display_name"`
+		Level       int     `json:"level"`
+		Score       float64 `json:"score"`
+	} `json:"concepts"`
+	Topics []struct {
+		DisplayName string  `json:"display_name"`
+		Score       float64 `json:"score"`
+	} `json:"topics"`
+	AbstractInvertedIndex map[string][]int `json:"abstract_inverted_index"`
+	IsParatext            bool             `json:"is_paratext"`
+	// SourceID of converted records. It must be set before conversion, e.g.
+	// with span-import -sid.
+	SourceID string `json:"-"`
+}
+
+// Abstract restores the abstract from its inverted index, which maps each
+// word to its positions.
+func (w *Work) Abstract() string {
+	var n int
+	for _, positions := range w.AbstractInvertedIndex {
+		for _, p := range positions {
+			n = max(n, p+1)
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	words := make([]string, n)
+	for word, positions := range w.AbstractInvertedIndex {
+		for _, p := range positions {
+			if p >= 0 {
+				words[p] = word
+			}
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(words, " ")), " ")
+}
+
+// ShortID returns the work id without URL prefix, e.g. "W2741809807".
+func (w *Work) ShortID() string {
+	return strings.TrimPrefix(w.ID, "https://openalex.org/")
+}
+
+// CrossrefType returns the crossref type of a work, which decides about
+// format and genre like for crossref records.
+func (w *Work) CrossrefType() string {
+	if w.TypeCrossref != "" {
+		return w.TypeCrossref
+	}
+	return crossrefTypes[w.Type]
+}
+
+// Date returns the publication date, or the first day of the publication
+// year.
+func (w *Work) Date() (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", w.PublicationDate); err == nil {
+		return t, nil
+	}
+	if w.PublicationYear > 0 {
+		return time.Date(w.PublicationYear, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Time{}, errors.New("openalex: no date found")
+}
+
+// ToIntermediateSchema converts a work. Paratext, like tables of contents,
+// and works without title are skipped.
+func (w *Work) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	if w.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	output.RecordID = w.ShortID()
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "openalex: missing id"}
+	}
+	if w.IsParatext {
+		return output, span.Skip{Reason: "openalex: paratext: " + output.RecordID}
+	}
+	output.SourceID = w.SourceID
+	output.ID = span.GenFincID(w.SourceID, output.RecordID)
+	output.MegaCollections = []string{"OpenAlex"}
+
+	title := strings.TrimSpace(w.Title)
+	if title == "" {
+		title = strings.TrimSpace(w.DisplayName)
+	}
+	if title == "" {
+		return output, span.Skip{Reason: "openalex: missing title: " + output.RecordID}
+	}
+	kind := w.CrossrefType()
+	output.Format = crossref.Formats.Lookup(kind, crossref.DefaultFormat)
+	output.Genre = crossref.Genres.Lookup(kind, "unknown")
+	output.RefType = crossref.RefTypes.Lookup(kind, "GEN")
+	output.Type = w.Type
+
+	var source Source
+	if w.PrimaryLocation != nil && w.PrimaryLocation.Source != nil {
+		source = *w.PrimaryLocation.Source
+	}
+	switch output.Genre {
+	case "book":
+		output.BookTitle = title
+	case "bookitem":
+		output.ArticleTitle = title
+		output.BookTitle = source.DisplayName
+	default:
+		output.ArticleTitle = title
+		output.JournalTitle = source.DisplayName
+	}
+	for _, issn := range append([]string{source.ISSNL}, source.ISSN...) {
+		if issn != "" && !slices.Contains(output.ISSN, issn) {
+			output.ISSN = append(output.ISSN, issn)
+		}
+	}
+	if source.HostOrganizationName != "" {
+		output.Publishers = []string{source.HostOrganizationName}
+	}
+
+	output.DOI = strings.ToLower(strings.TrimPrefix(w.DOI, "https://doi.org/"))
+	for _, a := range w.Authorships {
+		name := strings.TrimSpace(a.Author.DisplayName)
+		if name == "" {
+			name = strings.TrimSpace(a.RawAuthorName)
+		}
+		if name == "" {
+			continue
+		}
+		author := finc.Author{ID: a.Author.ORCID, Name: name}
+		for _, inst := range a.Institutions {
+			if inst.ROR != "" && !slices.Contains(author.ROR, inst.ROR) {
+				author.ROR = append(author.ROR, inst.ROR)
+			}
+		}
+		output.Authors = append(output.Authors, author)
+	}
+	output.Volume = w.Biblio.Volume
+	output.Issue = w.Biblio.Issue
+	output.StartPage = w.Biblio.FirstPage
+	output.EndPage = w.Biblio.LastPage
+	switch {
+	case output.StartPage != "" && output.EndPage != "" && output.StartPage != output.EndPage:
+		output.Pages = output.StartPage + "-" + output.EndPage
+	case output.StartPage != "":
+		output.Pages = output.StartPage
+	}
+	if t, err := w.Date(); err == nil {
+		output.Date = t
+		output.RawDate = t.Format("2006-01-02")
+	}
+	if lang := span.LanguageCode(w.Language); lang != "" {
+		output.Languages = []string{lang}
+	}
+	output.Abstract = w.Abstract()
+	for _, t := range w.Topics {
+		if t.DisplayName != "" && !slices.Contains(output.Subjects, t.DisplayName) {
+			output.Subjects = append(output.Subjects, t.DisplayName)
+		}
+	}
+	for _, c := range w.Concepts {
+		if c.Score >= MinConceptScore && c.DisplayName != "" && !slices.Contains(output.Subjects, c.DisplayName) {
+			output.Subjects = append(output.Subjects, c.DisplayName)
+		}
+	}
+	output.OpenAccess = w.OpenAccess.IsOA
+	if w.OpenAccess.IsOA {
+		output.OpenAccessEvidence = "openalex:" + w.OpenAccess.OAStatus
+	}
+	for _, loc := range []*Location{w.PrimaryLocation, w.BestOALocation} {
+		if loc != nil && loc.License != "" && !slices.Contains(output.License, loc.License) {
+			output.License = append(output.License, loc.License)
+		}
+	}
+	if output.DOI != "" {
+		output.URL = append(output.URL, "https://doi.org/"+output.DOI)
+	}
+	for _, u := range []string{w.OpenAccess.OAURL, landingPage(w.PrimaryLocation)} {
+		if u != "" && !slices.Contains(output.URL, u) {
+			output.URL = append(output.URL, u)
+		}
+	}
+	return output, nil
+}
+
+func landingPage(loc *Location) string {
+	if loc == nil {
+		return ""
+	}
+	return loc.LandingPageURL
+}
@@ -0,0 +1,178 @@
+package openalex
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/segmentio/encoding/json"
+)
+
+func TestWorkAbstract(t *testing.T) {
+	var cases = []struct {
+		index  map[string][]int
+		result string
+	}{
+		{nil, ""},
+		{map[string][]int{"Despite": {0}, "growing": {1}, "interest": {2}, "in": {3, 5}, "OA": {4}, "practice": {6}},
+			"Despite growing interest in OA in practice"},
+		{map[string][]int{"gap": {0}, "here": {3}}, "gap here"},
+		{map[string][]int{"negative": {-1}, "ok": {0}}, "ok"},
+	}
+	for _, c := range cases {
+		w := Work{AbstractInvertedIndex: c.index}
+		if got := w.Abstract(); got != c.result {
+			t.Errorf("Abstract(%v): got %q, want %q", c.index, got, c.result)
+		}
+	}
+}
+
+func TestWorkShortID(t *testing.T) {
+	var cases = []struct {
+		id     string
+		result string
+	}{
+		{"https://openalex.org/W2741809807", "W2741809807"},
+		{"W2741809807", "W2741809807"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		w := Work{ID: c.id}
+		if got := w.ShortID(); got != c.result {
+			t.Errorf("ShortID(%q): got %q, want %q", c.id, got, c.result)
+		}
+	}
+}
+
+func TestWorkCrossrefType(t *testing.T) {
+	var cases = []struct {
+		typ, typeCrossref string
+		result            string
+	}{
+		{"article", "journal-article", "journal-article"},
+		{"article", "proceedings-article", "proceedings-article"},
+		{"review", "", "journal-article"},
+		{"preprint", "", "posted-content"},
+		{"paratext", "", ""},
+	}
+	for _, c := range cases {
+		w := Work{Type: c.typ, TypeCrossref: c.typeCrossref}
+		if got := w.CrossrefType(); got != c.result {
+			t.Errorf("CrossrefType(%q, %q): got %q, want %q", c.typ, c.typeCrossref, got, c.result)
+		}
+	}
+}
+
+func TestWorkDate(t *testing.T) {
+	var cases = []struct {
+		date   string
+		year   int
+		result string
+	}{
+		{"2018-02-13", 2018, "2018-02-13"},
+		{"", 2018, "2018-01-01"},
+		{"2018", 2018, "2018-01-01"},
+		{"", 0, ""},
+	}
+	for _, c := range cases {
+		w := Work{PublicationDate: c.date, PublicationYear: c.year}
+		got, err := w.Date()
+		if c.result == "" {
+			if err == nil {
+				t.Errorf("Date(%q, %d): expected error", c.date, c.year)
+			}
+			continue
+		}
+		if err != nil || got.Format(time.DateOnly) != c.result {
+			t.Errorf("Date(%q, %d): got %v (%v), want %v", c.date, c.year, got.Format(time.DateOnly), err, c.result)
+		}
+	}
+}
+
+// TestWorkToIntermediateSchema converts testdata/sample.jsonl, an article, a
+// book and paratext, which is skipped.
+func TestWorkToIntermediateSchema(t *testing.T) {
+	f, err := os.Open("testdata/sample.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var (
+		scanner = bufio.NewScanner(f)
+		result  []*finc.IntermediateSchema
+		skipped int
+	)
+	for scanner.Scan() {
+		var w Work
+		if err := json.Unmarshal(scanner.Bytes(), &w); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Errorf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		w.SourceID = "999"
+		output, err := w.ToIntermediateSchema()
+		if _, ok := err.(span.Skip); ok {
+			skipped++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 1 {
+		t.Errorf("got %d skipped works, want 1", skipped)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d records, want 2", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-VzI3NDE4MDk4MDc"},
+		{0, "Genre", result[0].Genre, "article"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "The state of OA"},
+		{0, "JournalTitle", result[0].JournalTitle, "PeerJ"},
+		{0, "ISSN", result[0].ISSN, []string{"2167-8359"}},
+		{0, "Volume", result[0].Volume, "6"},
+		{0, "Pages", result[0].Pages, "e4375"},
+		{0, "RawDate", result[0].RawDate, "2018-02-13"},
+		{0, "Abstract", result[0].Abstract, "Despite growing interest in OA in practice"},
+		{0, "Authors", result[0].Authors, []finc.Author{
+			{ID: "https://orcid.org/0000-0003-1613-5981", Name: "Heather Piwowar", ROR: []string{"https://ror.org/02nr0ka47"}},
+			{Name: "Jason Priem"},
+		}},
+		{0, "DOI", result[0].DOI, "10.7717/peerj.4375"},
+		{0, "Languages", result[0].Languages, []string{"eng"}},
+		{0, "URL", result[0].URL, []string{
+			"https://doi.org/10.7717/peerj.4375",
+			"https://peerj.com/articles/4375.pdf",
+			"https://peerj.com/articles/4375",
+		}},
+		{0, "Subjects", result[0].Subjects, []string{"Scholarly Communication", "Open access"}},
+		{0, "OpenAccess", result[0].OpenAccess, true},
+		{0, "License", result[0].License, []string{"cc-by"}},
+		{0, "OpenAccessEvidence", result[0].OpenAccessEvidence, "openalex:gold"},
+		{1, "Genre", result[1].Genre, "book"},
+		{1, "BookTitle", result[1].BookTitle, "A book on things"},
+		{1, "RawDate", result[1].RawDate, "2020-01-01"},
+		{1, "Languages", result[1].Languages, []string{"deu"}},
+		{1, "OpenAccess", result[1].OpenAccess, false},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
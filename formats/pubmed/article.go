@@ -0,0 +1,365 @@
+// Package pubmed converts PubMed/MEDLINE XML, e.g. from the annual baseline
+// or daily update files, to intermediate schema. Files are streamed by
+// PubmedArticle element, DeleteCitation elements are ignored.
+//
+//	$ zcat pubmed25n0001.xml.gz | span-import -i pubmed -sid 999
+package pubmed
+
+import (
+	"encoding/xml"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+)
+
+var (
+	// tagPattern matches inline markup, like <i> or <sup>, in titles and
+	// abstracts.
+	tagPattern = regexp.MustCompile(`<[^>]+>`)
+	// medlineDatePattern finds year and optional month in a MedlineDate, like
+	// "1998 Dec-1999 Jan", "2000 Spring" or "1999-2000".
+	medlineDatePattern = regexp.MustCompile(`([0-9]{4})(?:\s+([A-Za-z]{3}))?`)
+
+	// seasons map to the first month of a season.
+	seasons = map[string]string{
+		"spr": "Mar", "sum": "Jun", "fal": "Sep", "aut": "Sep", "win": "Dec",
+	}
+)
+
+// Date is a PubDate, ArticleDate or similar. PubDate may contain a
+// MedlineDate instead of its parts.
+type Date struct {
+	Year        string `xml:"Year"`
+	Month       string `xml:"Month"`
+	Day         string `xml:"Day"`
+	Season      string `xml:"Season"`
+	MedlineDate string `xml:"MedlineDate"`
+}
+
+// Time parses a date. Months are given as "May" or "05", missing months or
+// days default to January or the first. A MedlineDate is used, if there is
+// no year.
+func (d Date) Time() (time.Time, error) {
+	year, month, day := strings.TrimSpace(d.Year), strings.TrimSpace(d.Month), strings.TrimSpace(d.Day)
+	if year == "" {
+		m := medlineDatePattern.FindStringSubmatch(d.MedlineDate)
+		if m == nil {
+			return time.Time{}, errors.New("pubmed: no date found")
+		}
+		year, month, day = m[1], m[2], ""
+	}
+	if month == "" && d.Season != "" {
+		month = d.Season
+	}
+	if len(month) >= 3 {
+		if v, ok := seasons[strings.ToLower(month[:3])]; ok {
+			month = v
+		}
+	}
+	if day == "" {
+		day = "1"
+	}
+	for _, layout := range []string{"2006 Jan 2", "2006 1 2"} {
+		if t, err := time.Parse(layout, year+" "+month+" "+day); err == nil {
+			return t, nil
+		}
+	}
+	return time.Parse("2006", year)
+}
+
+// innerText is an element with inline markup, like an ArticleTitle with
+// <i> or <sup>.
+type innerText struct {
+	Inner string `xml:",innerxml"`
+}
+
+// String returns the text without markup, with entities resolved.
+func (t innerText) String() string {
+	s := tagPattern.ReplaceAllString(t.Inner, "")
+	var v string
+	if err := xml.Unmarshal([]byte("<x>"+s+"</x>"), &v); err == nil {
+		s = v
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Article is a single PubmedArticle.
+type Article struct {
+	XMLName         xml.Name `xml:"PubmedArticle"`
+	MedlineCitation struct {
+		Status  string `xml:"Status,attr"`
+		PMID    string `xml:"PMID"`
+		Article struct {
+			Journal struct {
+				ISSN []struct {
+					Text     string `xml:",chardata"`
+					IssnType string `xml:"IssnType,attr"`
+				} `xml:"ISSN"`
+				JournalIssue struct {
+					Volume  string `xml:"Volume"`
+					Issue   string `xml:"Issue"`
+					PubDate Date   `xml:"PubDate"`
+				} `xml:"JournalIssue"`
+				Title           string `xml:"Title"`
+				ISOAbbreviation string `xml:"ISOAbbreviation"`
+			} `xml:"Journal"`
+			ArticleTitle innerText `xml:"ArticleTitle"`
+			Pagination   struct {
+				StartPage  string `xml:"StartPage"`
+				EndPage    string `xml:"EndPage"`
+				MedlinePgn string `xml:"MedlinePgn"`
+			} `xml:"Pagination"`
+			ELocationID []struct {
+				Text    string `xml:",chardata"`
+				EIdType string `xml:"EIdType,attr"`
+			} `xml:"ELocationID"`
+			Abstract []struct {
+				innerText
+				Label string `xml:"Label,attr"`
+			} `xml:"Abstract>AbstractText"`
+			Authors []struct {
+				LastName       string `xml:"LastName"`
+				ForeName       string `xml:"ForeName"`
+				Initials       string `xml:"Initials"`
+				Suffix         string `xml:"Suffix"`
+				CollectiveName string `xml:"CollectiveName"`
+				Identifier     []struct {
+					Text   string `xml:",chardata"`
+					Source string `xml:"Source,attr"`
+				} `xml:"Identifier"`
+			} `xml:"AuthorList>Author"`
+			Language         []string  `xml:"Language"`
+			PublicationTypes []string  `xml:"PublicationTypeList>PublicationType"`
+			VernacularTitle  innerText `xml:"VernacularTitle"`
+		} `xml:"Article"`
+		MedlineJournalInfo struct {
+			MedlineTA   string `xml:"MedlineTA"`
+			ISSNLinking string `xml:"ISSNLinking"`
+		} `xml:"MedlineJournalInfo"`
+		MeshHeadings []struct {
+			Descriptor struct {
+				Text         string `xml:",chardata"`
+				UI           string `xml:"UI,attr"`
+				MajorTopicYN string `xml:"MajorTopicYN,attr"`
+			} `xml:"DescriptorName"`
+			Qualifiers []struct {
+				Text         string `xml:",chardata"`
+				UI           string `xml:"UI,attr"`
+				MajorTopicYN string `xml:"MajorTopicYN,attr"`
+			} `xml:"QualifierName"`
+		} `xml:"MeshHeadingList>MeshHeading"`
+		Keywords []string `xml:"KeywordList>Keyword"`
+	} `xml:"MedlineCitation"`
+	PubmedData struct {
+		PublicationStatus string `xml:"PublicationStatus"`
+		ArticleIDs        []struct {
+			Text   string `xml:",chardata"`
+			IdType string `xml:"IdType,attr"`
+		} `xml:"ArticleIdList>ArticleId"`
+	} `xml:"PubmedData"`
+	// SourceID of converted records. It must be set before conversion, e.g.
+	// with span-import -sid.
+	SourceID string `xml:"-"`
+}
+
+// ArticleID returns the first article id of a given type, e.g. "doi" or
+// "pubmed".
+func (a *Article) ArticleID(idType string) string {
+	for _, id := range a.PubmedData.ArticleIDs {
+		if id.IdType == idType {
+			return strings.TrimSpace(id.Text)
+		}
+	}
+	return ""
+}
+
+// PMID returns the PubMed id.
+func (a *Article) PMID() string {
+	if v := strings.TrimSpace(a.MedlineCitation.PMID); v != "" {
+		return v
+	}
+	return a.ArticleID("pubmed")
+}
+
+// DOI returns the DOI from the article ids or from an electronic location.
+func (a *Article) DOI() string {
+	if v := a.ArticleID("doi"); v != "" {
+		return v
+	}
+	for _, loc := range a.MedlineCitation.Article.ELocationID {
+		if loc.EIdType == "doi" {
+			return strings.TrimSpace(loc.Text)
+		}
+	}
+	return ""
+}
+
+// Headings returns MeSH headings, with qualifiers, like
+// "Neoplasms/therapy". Major topics are marked with an asterisk, as in
+// PubMed.
+func (a *Article) Headings() (headings []string) {
+	for _, h := range a.MedlineCitation.MeshHeadings {
+		descriptor := strings.TrimSpace(h.Descriptor.Text)
+		if descriptor == "" {
+			continue
+		}
+		if h.Descriptor.MajorTopicYN == "Y" {
+			descriptor += "*"
+		}
+		if len(h.Qualifiers) == 0 {
+			headings = append(headings, descriptor)
+			continue
+		}
+		for _, q := range h.Qualifiers {
+			qualifier := strings.TrimSpace(q.Text)
+			if q.MajorTopicYN == "Y" {
+				qualifier += "*"
+			}
+			headings = append(headings, descriptor+"/"+qualifier)
+		}
+	}
+	return headings
+}
+
+// Subjects returns MeSH descriptors and keywords, without duplicates.
+func (a *Article) Subjects() (subjects []string) {
+	var values []string
+	for _, h := range a.MedlineCitation.MeshHeadings {
+		values = append(values, h.Descriptor.Text)
+	}
+	values = append(values, a.MedlineCitation.Keywords...)
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" && !slices.Contains(subjects, v) {
+			subjects = append(subjects, v)
+		}
+	}
+	return subjects
+}
+
+// Title returns the article title, or the vernacular title. Brackets, that
+// mark translated titles, are removed.
+func (a *Article) Title() string {
+	title := a.MedlineCitation.Article.ArticleTitle.String()
+	if title == "" {
+		title = a.MedlineCitation.Article.VernacularTitle.String()
+	}
+	if strings.HasPrefix(title, "[") {
+		if t, ok := strings.CutSuffix(strings.TrimSuffix(title, "."), "]"); ok {
+			title = t[1:]
+		}
+	}
+	return title
+}
+
+// ToIntermediateSchema converts an article. The PMID is the record id,
+// articles without title are skipped.
+func (a *Article) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	if a.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	output.RecordID = a.PMID()
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "pubmed: missing PMID"}
+	}
+	output.SourceID = a.SourceID
+	output.ID = span.GenFincID(a.SourceID, output.RecordID)
+	output.MegaCollections = []string{"PubMed"}
+	output.Format = "ElectronicArticle"
+	output.Genre = "article"
+	output.RefType = "EJOUR"
+
+	article := a.MedlineCitation.Article
+	if output.ArticleTitle = a.Title(); output.ArticleTitle == "" {
+		return output, span.Skip{Reason: "pubmed: missing title: " + output.RecordID}
+	}
+	output.JournalTitle = strings.TrimSpace(article.Journal.Title)
+	output.ShortTitle = strings.TrimSpace(article.Journal.ISOAbbreviation)
+	output.DOI = a.DOI()
+
+	if v := strings.TrimSpace(a.MedlineCitation.MedlineJournalInfo.ISSNLinking); v != "" {
+		output.ISSN = append(output.ISSN, v)
+	}
+	for _, issn := range article.Journal.ISSN {
+		v := strings.TrimSpace(issn.Text)
+		if v == "" || slices.Contains(output.ISSN, v) || slices.Contains(output.EISSN, v) {
+			continue
+		}
+		if issn.IssnType == "Electronic" {
+			output.EISSN = append(output.EISSN, v)
+		} else {
+			output.ISSN = append(output.ISSN, v)
+		}
+	}
+	output.Volume = strings.TrimSpace(article.Journal.JournalIssue.Volume)
+	output.Issue = strings.TrimSpace(article.Journal.JournalIssue.Issue)
+	output.StartPage = strings.TrimSpace(article.Pagination.StartPage)
+	output.EndPage = strings.TrimSpace(article.Pagination.EndPage)
+	if pgn := strings.TrimSpace(article.Pagination.MedlinePgn); pgn != "" {
+		output.Pages = pgn
+		if output.StartPage == "" {
+			// MedlinePgn abbreviates, e.g. "145-9" for 145-149.
+			output.StartPage, _, _ = strings.Cut(pgn, "-")
+		}
+	} else if output.StartPage != "" && output.EndPage != "" {
+		output.Pages = output.StartPage + "-" + output.EndPage
+	}
+	if t, err := article.Journal.JournalIssue.PubDate.Time(); err == nil {
+		output.Date = t
+		output.RawDate = t.Format("2006-01-02")
+	}
+	for _, author := range article.Authors {
+		if author.CollectiveName != "" {
+			output.Authors = append(output.Authors, finc.Author{Corporate: strings.TrimSpace(author.CollectiveName)})
+			continue
+		}
+		v := finc.Author{
+			LastName:  strings.TrimSpace(author.LastName),
+			FirstName: strings.TrimSpace(author.ForeName),
+			Initial:   strings.TrimSpace(author.Initials),
+			Suffix:    strings.TrimSpace(author.Suffix),
+		}
+		if v.LastName == "" {
+			continue
+		}
+		v.Name = v.LastName
+		if v.FirstName != "" {
+			v.Name = v.LastName + ", " + v.FirstName
+		}
+		for _, id := range author.Identifier {
+			if id.Source == "ORCID" {
+				v.ID = strings.TrimSpace(id.Text)
+				if !strings.HasPrefix(v.ID, "http") {
+					v.ID = "https://orcid.org/" + v.ID
+				}
+			}
+		}
+		output.Authors = append(output.Authors, v)
+	}
+	for _, lang := range article.Language {
+		if v := span.LanguageCode(lang); v != "" && !slices.Contains(output.Languages, v) {
+			output.Languages = append(output.Languages, v)
+		}
+	}
+	var abstract []string
+	for _, text := range article.Abstract {
+		s := text.String()
+		if text.Label != "" && s != "" {
+			s = text.Label + ": " + s
+		}
+		abstract = append(abstract, s)
+	}
+	output.Abstract = strings.TrimSpace(strings.Join(abstract, "\n"))
+	output.Headings = a.Headings()
+	output.Subjects = a.Subjects()
+	output.URL = []string{"https://pubmed.ncbi.nlm.nih.gov/" + output.RecordID + "/"}
+	if output.DOI != "" {
+		output.URL = append(output.URL, "https://doi.org/"+output.DOI)
+	}
+	return output, nil
+}
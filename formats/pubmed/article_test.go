@@ -0,0 +1,210 @@
+package pubmed
+
+import (
+	"encoding/xml"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/xmlstream"
+)
+
+// parseArticle parses a single PubmedArticle.
+func parseArticle(t *testing.T, s string) *Article {
+	t.Helper()
+	var a Article
+	if err := xml.Unmarshal([]byte(s), &a); err != nil {
+		t.Fatal(err)
+	}
+	return &a
+}
+
+func TestDateTime(t *testing.T) {
+	var cases = []struct {
+		date   Date
+		result string
+	}{
+		{Date{Year: "2019", Month: "May", Day: "12"}, "2019-05-12"},
+		{Date{Year: "2019", Month: "05"}, "2019-05-01"},
+		{Date{Year: "2019"}, "2019-01-01"},
+		{Date{Year: "2019", Season: "Summer"}, "2019-06-01"},
+		{Date{MedlineDate: "1998 Dec-1999 Jan"}, "1998-12-01"},
+		{Date{MedlineDate: "2000 Spring"}, "2000-03-01"},
+		{Date{MedlineDate: "1999-2000"}, "1999-01-01"},
+	}
+	for _, c := range cases {
+		got, err := c.date.Time()
+		if err != nil {
+			t.Errorf("%v: %v", c.date, err)
+			continue
+		}
+		if got.Format(time.DateOnly) != c.result {
+			t.Errorf("%v: got %v, want %v", c.date, got.Format(time.DateOnly), c.result)
+		}
+	}
+	if _, err := (Date{MedlineDate: "n.d."}).Time(); err == nil {
+		t.Errorf("expected error for date without year")
+	}
+}
+
+func TestArticleDOI(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{`<PubmedArticle/>`, ""},
+		{`<PubmedArticle><PubmedData><ArticleIdList>
+			<ArticleId IdType="pubmed">1</ArticleId>
+			<ArticleId IdType="doi"> 10.1000/xyz.123 </ArticleId>
+		</ArticleIdList></PubmedData></PubmedArticle>`, "10.1000/xyz.123"},
+		{`<PubmedArticle><MedlineCitation><Article>
+			<ELocationID EIdType="pii">S0001</ELocationID>
+			<ELocationID EIdType="doi">10.1000/elocation</ELocationID>
+		</Article></MedlineCitation></PubmedArticle>`, "10.1000/elocation"},
+	}
+	for _, c := range cases {
+		if got := parseArticle(t, c.s).DOI(); got != c.result {
+			t.Errorf("DOI(%s): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+func TestArticleHeadings(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result []string
+	}{
+		{`<PubmedArticle/>`, nil},
+		{`<PubmedArticle><MedlineCitation><MeshHeadingList>
+			<MeshHeading><DescriptorName MajorTopicYN="N">Animals</DescriptorName></MeshHeading>
+			<MeshHeading><DescriptorName MajorTopicYN="Y">Mice</DescriptorName></MeshHeading>
+		</MeshHeadingList></MedlineCitation></PubmedArticle>`, []string{"Animals", "Mice*"}},
+		{`<PubmedArticle><MedlineCitation><MeshHeadingList><MeshHeading>
+			<DescriptorName MajorTopicYN="N">Neoplasms</DescriptorName>
+			<QualifierName MajorTopicYN="Y">therapy</QualifierName>
+			<QualifierName MajorTopicYN="N">genetics</QualifierName>
+		</MeshHeading></MeshHeadingList></MedlineCitation></PubmedArticle>`,
+			[]string{"Neoplasms/therapy*", "Neoplasms/genetics"}},
+	}
+	for _, c := range cases {
+		if got := parseArticle(t, c.s).Headings(); !reflect.DeepEqual(got, c.result) {
+			t.Errorf("Headings(%s): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+func TestArticleSubjects(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result []string
+	}{
+		{`<PubmedArticle/>`, nil},
+		{`<PubmedArticle><MedlineCitation>
+			<MeshHeadingList><MeshHeading><DescriptorName>Neoplasms</DescriptorName></MeshHeading></MeshHeadingList>
+			<KeywordList><Keyword>cancer</Keyword><Keyword> Neoplasms </Keyword><Keyword></Keyword></KeywordList>
+		</MedlineCitation></PubmedArticle>`, []string{"Neoplasms", "cancer"}},
+	}
+	for _, c := range cases {
+		if got := parseArticle(t, c.s).Subjects(); !reflect.DeepEqual(got, c.result) {
+			t.Errorf("Subjects(%s): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+func TestArticleTitle(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{`<PubmedArticle><MedlineCitation><Article>
+			<ArticleTitle>Effects of <i>E. coli</i> on CO<sub>2</sub> &amp; more.</ArticleTitle>
+		</Article></MedlineCitation></PubmedArticle>`, "Effects of E. coli on CO2 & more."},
+		{`<PubmedArticle><MedlineCitation><Article>
+			<ArticleTitle>[On examples].</ArticleTitle>
+		</Article></MedlineCitation></PubmedArticle>`, "On examples"},
+		{`<PubmedArticle><MedlineCitation><Article>
+			<VernacularTitle>Über Beispiele.</VernacularTitle>
+		</Article></MedlineCitation></PubmedArticle>`, "Über Beispiele."},
+	}
+	for _, c := range cases {
+		if got := parseArticle(t, c.s).Title(); got != c.result {
+			t.Errorf("Title(%s): got %q, want %q", c.s, got, c.result)
+		}
+	}
+}
+
+// TestArticleToIntermediateSchema converts testdata/sample.xml, the article
+// without PMID is skipped.
+func TestArticleToIntermediateSchema(t *testing.T) {
+	f, err := os.Open("testdata/sample.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var (
+		scanner = xmlstream.NewScanner(f, new(Article))
+		result  []*finc.IntermediateSchema
+	)
+	for scanner.Scan() {
+		a := scanner.Element().(*Article)
+		if _, err := a.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Errorf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		a.SourceID = "999"
+		output, err := a.ToIntermediateSchema()
+		if _, ok := err.(span.Skip); ok {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("got %d records, want 2", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-MzEwMDAwMDE"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "Effects of E. coli on CO2 & more."},
+		{0, "JournalTitle", result[0].JournalTitle, "Journal of Examples"},
+		{0, "ShortTitle", result[0].ShortTitle, "J Ex"},
+		{0, "ISSN", result[0].ISSN, []string{"1234-5678"}},
+		{0, "EISSN", result[0].EISSN, []string{"1234-5679"}},
+		{0, "Volume", result[0].Volume, "12"},
+		{0, "Issue", result[0].Issue, "3"},
+		{0, "Pages", result[0].Pages, "145-9"},
+		{0, "RawDate", result[0].RawDate, "1998-12-01"},
+		{0, "Abstract", result[0].Abstract, "BACKGROUND: Some bold text.\nRESULTS: Numbers."},
+		{0, "Authors", result[0].Authors, []finc.Author{
+			{ID: "https://orcid.org/0000-0002-1825-0097", Name: "Doe, Jane", LastName: "Doe", FirstName: "Jane", Initial: "J"},
+			{Corporate: "Example Study Group"},
+		}},
+		{0, "DOI", result[0].DOI, "10.1000/xyz.123"},
+		{0, "Languages", result[0].Languages, []string{"eng"}},
+		{0, "URL", result[0].URL, []string{"https://pubmed.ncbi.nlm.nih.gov/31000001/", "https://doi.org/10.1000/xyz.123"}},
+		{0, "Headings", result[0].Headings, []string{"Animals", "Neoplasms/therapy*"}},
+		{0, "Subjects", result[0].Subjects, []string{"Animals", "Neoplasms", "cancer"}},
+		{1, "ArticleTitle", result[1].ArticleTitle, "On examples"},
+		{1, "ISSN", result[1].ISSN, []string{"0028-0836"}},
+		{1, "Pages", result[1].Pages, "1-10"},
+		{1, "RawDate", result[1].RawDate, "2019-05-01"},
+		{1, "DOI", result[1].DOI, "10.1000/elocation"},
+		{1, "Languages", result[1].Languages, []string{"deu"}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
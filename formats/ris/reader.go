@@ -0,0 +1,101 @@
+package ris
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// tagPattern matches a tagged line, like "AU  - Doe, Jane". Some exports use
+// a single space or omit the space after the dash.
+var tagPattern = regexp.MustCompile(`^([A-Z][A-Z0-9])\s{1,2}-(?:\s(.*))?$`)
+
+// Reader reads RIS records from a stream.
+type Reader struct {
+	scanner *bufio.Scanner
+	lineNum int
+	pending string // TY line of the next record, if ER was missing.
+	line    string
+}
+
+// next advances to the next line.
+func (r *Reader) next() bool {
+	if r.pending != "" {
+		r.line, r.pending = r.pending, ""
+		return true
+	}
+	if !r.scanner.Scan() {
+		return false
+	}
+	r.lineNum++
+	r.line = strings.TrimRight(r.scanner.Text(), " \t\r")
+	if r.lineNum == 1 {
+		r.line = strings.TrimPrefix(r.line, "\ufeff")
+	}
+	return true
+}
+
+// NewReader returns a reader for RIS records.
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Read returns the next record or io.EOF, if there are no more records. A
+// record without ER ends at the next TY or at the end of the input.
+func (r *Reader) Read() (*Record, error) {
+	var (
+		record  *Record
+		lastTag string
+	)
+	for r.next() {
+		line := r.line
+		m := tagPattern.FindStringSubmatch(line)
+		if m == nil {
+			// Continuation of a multi-line value; keywords are listed one
+			// per line.
+			line = strings.TrimSpace(line)
+			if record == nil || lastTag == "" || line == "" {
+				continue
+			}
+			values := record.Tags[lastTag]
+			switch {
+			case lastTag == "KW":
+				record.Tags[lastTag] = append(values, line)
+			case values[len(values)-1] == "":
+				values[len(values)-1] = line
+			default:
+				values[len(values)-1] += " " + line
+			}
+			continue
+		}
+		tag, value := m[1], strings.TrimSpace(m[2])
+		switch {
+		case tag == "ER":
+			if record != nil {
+				return record, nil
+			}
+			continue
+		case tag == "TY":
+			if record != nil {
+				r.pending = line
+				return record, nil
+			}
+			record = &Record{Tags: make(map[string][]string)}
+		case record == nil:
+			// Tag outside of a record.
+			continue
+		}
+		record.Tags[tag] = append(record.Tags[tag], value)
+		lastTag = tag
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if record != nil {
+		return record, nil
+	}
+	return nil, io.EOF
+}
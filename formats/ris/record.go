@@ -0,0 +1,257 @@
+// Package ris reads RIS exports, as sent by small publishers and
+// institutional repositories, and converts them to intermediate schema.
+//
+// A record is a sequence of tagged lines, starting with TY and ending with ER:
+//
+//	TY  - JOUR
+//	TI  - An article
+//	AU  - Doe, Jane
+//	PY  - 2019
+//	ER  -
+//
+// Lines not starting with a tag continue the value of the previous tag.
+package ris
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+)
+
+var (
+	// datePattern matches RIS dates, like "2019", "2019/05" or "2019/05/12/".
+	datePattern = regexp.MustCompile(`^([0-9]{4})(?:[/-]([0-9]{1,2}))?(?:[/-]([0-9]{1,2}))?`)
+	// issnPattern matches a ISSN, with or without hyphen.
+	issnPattern = regexp.MustCompile(`^[0-9]{4}-?[0-9]{3}[0-9Xx]$`)
+)
+
+// formats maps RIS reference types to genre and finc format.
+var formats = map[string][2]string{
+	"ABST":   {"article", "ElectronicArticle"},
+	"BOOK":   {"book", "eBook"},
+	"CHAP":   {"bookitem", "ElectronicBookPart"},
+	"CONF":   {"proceeding", "ElectronicProceeding"},
+	"CPAPER": {"proceeding", "ElectronicConferenceProceeding"},
+	"EBOOK":  {"book", "eBook"},
+	"ECHAP":  {"bookitem", "ElectronicBookPart"},
+	"EJOUR":  {"article", "ElectronicArticle"},
+	"INPR":   {"article", "ElectronicArticle"},
+	"JFULL":  {"unknown", "ElectronicJournal"},
+	"JOUR":   {"article", "ElectronicArticle"},
+	"MGZN":   {"article", "ElectronicArticle"},
+	"NEWS":   {"article", "ElectronicArticle"},
+	"RPRT":   {"report", "ElectronicResourceRemoteAccess"},
+	"SER":    {"book", "eBook"},
+	"THES":   {"document", "ElectronicThesis"},
+}
+
+// GenreFormat returns genre and finc format for a RIS reference type, e.g.
+// "article" and "ElectronicArticle" for JOUR. Unknown types are documents.
+func GenreFormat(refType string) (genre, format string) {
+	if v, ok := formats[refType]; ok {
+		return v[0], v[1]
+	}
+	return "document", "ElectronicResourceRemoteAccess"
+}
+
+// Record is a single RIS record, values of a tag in order of appearance.
+type Record struct {
+	Tags map[string][]string
+	// SourceID and MegaCollections are not part of RIS, they are set per
+	// delivery.
+	SourceID        string
+	MegaCollections []string
+}
+
+// First returns the first non-empty value of the first tag, that has one.
+func (r *Record) First(tags ...string) string {
+	for _, tag := range tags {
+		for _, v := range r.Tags[tag] {
+			if v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// All returns all non-empty values of all tags, without duplicates.
+func (r *Record) All(tags ...string) (result []string) {
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for _, v := range r.Tags[tag] {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// ParseAuthor splits "Doe, Jane" or "Jane Doe" into first and last name.
+func ParseAuthor(s string) finc.Author {
+	s = strings.Join(strings.Fields(s), " ")
+	if last, first, ok := strings.Cut(s, ","); ok {
+		// "Doe, Jane, Jr." has a suffix.
+		first, suffix, _ := strings.Cut(first, ",")
+		return finc.Author{
+			Name:      s,
+			LastName:  strings.TrimSpace(last),
+			FirstName: strings.TrimSpace(first),
+			Suffix:    strings.TrimSpace(suffix),
+		}
+	}
+	if i := strings.LastIndex(s, " "); i > 0 {
+		return finc.Author{Name: s, FirstName: s[:i], LastName: s[i+1:]}
+	}
+	return finc.Author{Name: s, LastName: s}
+}
+
+// ParseDate parses a date, like "2019/05/12/", "2019-05" or "2019". Missing
+// month or day default to January or the first.
+func ParseDate(s string) (time.Time, error) {
+	m := datePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return time.Time{}, errors.New("ris: no date found")
+	}
+	month, day := "01", "01"
+	if m[2] != "" {
+		month = leftPad(m[2])
+	}
+	if m[3] != "" {
+		day = leftPad(m[3])
+	}
+	t, err := time.Parse("2006-01-02", m[1]+"-"+month+"-"+day)
+	if err != nil {
+		// Invalid month or day, e.g. "2019/00/00".
+		return time.Parse("2006", m[1])
+	}
+	return t, nil
+}
+
+func leftPad(s string) string {
+	if len(s) == 1 {
+		return "0" + s
+	}
+	return s
+}
+
+// ID returns a record id: the RIS ID, the DOI or the accession number.
+func (r *Record) ID() string {
+	if id := r.First("ID"); id != "" {
+		return id
+	}
+	if doi := r.DOI(); doi != "" {
+		return doi
+	}
+	return r.First("AN")
+}
+
+// DOI returns the DOI from the DO tag or from a link.
+func (r *Record) DOI() string {
+	for _, v := range r.All("DO", "UR", "L2") {
+		if doi := span.FindDOI(v); doi != "" {
+			return doi
+		}
+	}
+	return ""
+}
+
+// ToIntermediateSchema converts a RIS record. The record must have an id, a
+// DOI or an accession number and a title.
+func (r *Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
+	if r.SourceID == "" {
+		return nil, span.ErrNoSourceID
+	}
+	output := finc.NewIntermediateSchema()
+	output.RecordID = r.ID()
+	if output.RecordID == "" {
+		return output, span.Skip{Reason: "ris: missing record id"}
+	}
+	output.SourceID = r.SourceID
+	output.ID = span.GenFincID(output.SourceID, output.RecordID)
+	output.MegaCollections = r.MegaCollections
+
+	output.RefType = strings.ToUpper(r.First("TY"))
+	output.Genre, output.Format = GenreFormat(output.RefType)
+	output.Database = r.First("DB")
+	output.DataProvider = r.First("DP")
+
+	title := r.First("TI", "T1", "CT")
+	if title == "" {
+		return output, span.Skip{Reason: "ris: missing title: " + output.RecordID}
+	}
+	container := r.First("T2", "JF", "JO", "BT", "JA", "J2")
+	switch output.Genre {
+	case "book":
+		output.BookTitle = title
+	case "unknown":
+		// A journal as a whole, JFULL, refs. crossref "journal".
+		output.ArticleTitle = title
+		output.JournalTitle = title
+	case "bookitem", "proceeding":
+		output.ArticleTitle = title
+		output.BookTitle = container
+	default:
+		output.ArticleTitle = title
+		output.JournalTitle = container
+	}
+	output.ShortTitle = r.First("ST")
+	output.Series = r.First("T3")
+
+	for _, name := range r.All("AU", "A1") {
+		output.Authors = append(output.Authors, ParseAuthor(name))
+	}
+	for _, v := range r.All("SN") {
+		for _, id := range strings.FieldsFunc(v, func(r rune) bool { return r == ';' || r == ',' }) {
+			// Drop qualifiers, like "1234-5678 (Print)".
+			id, _, _ = strings.Cut(strings.TrimSpace(id), " (")
+			if issnPattern.MatchString(id) {
+				id = strings.ToUpper(id)
+				if !strings.Contains(id, "-") {
+					id = id[:4] + "-" + id[4:]
+				}
+				output.ISSN = append(output.ISSN, id)
+			} else if isbn := licensing.NormalizeISBN(id); isbn != "" {
+				output.ISBN = append(output.ISBN, isbn)
+			}
+		}
+	}
+	output.DOI = r.DOI()
+	output.Volume = r.First("VL")
+	output.Issue = r.First("IS", "CP")
+	output.StartPage = r.First("SP")
+	output.EndPage = r.First("EP")
+	if sp, ep, ok := strings.Cut(output.StartPage, "-"); ok && output.EndPage == "" {
+		// Some exports put the whole range into SP.
+		output.StartPage, output.EndPage = strings.TrimSpace(sp), strings.TrimSpace(ep)
+	}
+	switch {
+	case output.StartPage != "" && output.EndPage != "":
+		output.Pages = output.StartPage + "-" + output.EndPage
+	case output.StartPage != "":
+		output.Pages = output.StartPage
+	}
+	output.Edition = r.First("ET")
+	output.Publishers = r.All("PB")
+	output.Places = r.All("CY", "PP")
+	if t, err := ParseDate(r.First("DA", "PY", "Y1")); err == nil {
+		output.Date = t
+		output.RawDate = t.Format("2006-01-02")
+	}
+	for _, v := range r.All("LA") {
+		if lang := span.LanguageCode(v); lang != "" {
+			output.Languages = append(output.Languages, lang)
+		}
+	}
+	output.Abstract = r.First("AB", "N2")
+	output.Subjects = r.All("KW")
+	output.URL = r.All("UR", "L2")
+	return output, nil
+}
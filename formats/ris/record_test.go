@@ -0,0 +1,192 @@
+package ris
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miku/span"
+	"github.com/miku/span/formats/finc"
+)
+
+func readSample(t *testing.T) (records []*Record) {
+	f, err := os.Open("testdata/sample.ris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := NewReader(f)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+func TestReader(t *testing.T) {
+	records := readSample(t)
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+	var cases = []struct {
+		record int
+		tag    string
+		result []string
+	}{
+		{0, "TY", []string{"JOUR"}},
+		{0, "TI", []string{"A title spanning two lines"}},
+		{0, "AU", []string{"Doe, Jane", "Hans Müller"}},
+		{0, "KW", []string{"first", "second"}},
+		{1, "PB", []string{"Example Press"}},
+		{2, "SN", []string{"1234-5679; ISBN"}},
+		{3, "TI", []string{"Without ER or id"}},
+	}
+	for _, c := range cases {
+		if got := records[c.record].Tags[c.tag]; !reflect.DeepEqual(got, c.result) {
+			t.Errorf("record %d, %s: got %q, want %q", c.record, c.tag, got, c.result)
+		}
+	}
+}
+
+func TestParseAuthor(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result finc.Author
+	}{
+		{"Doe, Jane", finc.Author{Name: "Doe, Jane", LastName: "Doe", FirstName: "Jane"}},
+		{"Doe, Jane, Jr.", finc.Author{Name: "Doe, Jane, Jr.", LastName: "Doe", FirstName: "Jane", Suffix: "Jr."}},
+		{"Hans  Müller", finc.Author{Name: "Hans Müller", LastName: "Müller", FirstName: "Hans"}},
+		{"Plato", finc.Author{Name: "Plato", LastName: "Plato"}},
+	}
+	for _, c := range cases {
+		if got := ParseAuthor(c.s); !reflect.DeepEqual(got, c.result) {
+			t.Errorf("ParseAuthor(%q): got %+v, want %+v", c.s, got, c.result)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+		err    bool
+	}{
+		{"2019/05/12/", "2019-05-12", false},
+		{"2019/5", "2019-05-01", false},
+		{"2019-05-12", "2019-05-12", false},
+		{"2019///Spring", "2019-01-01", false},
+		{"2019/00/00", "2019-01-01", false},
+		{"n.d.", "", true},
+	}
+	for _, c := range cases {
+		got, err := ParseDate(c.s)
+		if (err != nil) != c.err {
+			t.Errorf("ParseDate(%q): got error %v", c.s, err)
+			continue
+		}
+		if err == nil && got.Format(time.DateOnly) != c.result {
+			t.Errorf("ParseDate(%q): got %v, want %v", c.s, got.Format(time.DateOnly), c.result)
+		}
+	}
+}
+
+func TestGenreFormat(t *testing.T) {
+	var cases = []struct {
+		refType string
+		genre   string
+		format  string
+	}{
+		{"JOUR", "article", "ElectronicArticle"},
+		{"BOOK", "book", "eBook"},
+		{"CHAP", "bookitem", "ElectronicBookPart"},
+		{"XYZ", "document", "ElectronicResourceRemoteAccess"},
+	}
+	for _, c := range cases {
+		if genre, format := GenreFormat(c.refType); genre != c.genre || format != c.format {
+			t.Errorf("GenreFormat(%q): got %v %v, want %v %v", c.refType, genre, format, c.genre, c.format)
+		}
+	}
+}
+
+func TestRecordID(t *testing.T) {
+	var cases = []struct {
+		tags map[string][]string
+		id   string
+		doi  string
+	}{
+		{map[string][]string{"ID": {"a"}, "DO": {"10.1000/x"}}, "a", "10.1000/x"},
+		{map[string][]string{"DO": {"doi:10.1000/x."}, "AN": {"123"}}, "10.1000/x", "10.1000/x"},
+		{map[string][]string{"UR": {"https://doi.org/10.1000/x"}}, "10.1000/x", "10.1000/x"},
+		{map[string][]string{"AN": {"123"}}, "123", ""},
+	}
+	for _, c := range cases {
+		r := &Record{Tags: c.tags}
+		if got := r.ID(); got != c.id {
+			t.Errorf("ID %v: got %q, want %q", c.tags, got, c.id)
+		}
+		if got := r.DOI(); got != c.doi {
+			t.Errorf("DOI %v: got %q, want %q", c.tags, got, c.doi)
+		}
+	}
+}
+
+// TestRecordToIntermediateSchema converts testdata/sample.ris, the record
+// without id is skipped.
+func TestRecordToIntermediateSchema(t *testing.T) {
+	var result []*finc.IntermediateSchema
+	for _, r := range readSample(t) {
+		if _, err := r.ToIntermediateSchema(); err != span.ErrNoSourceID {
+			t.Errorf("got %v, want %v", err, span.ErrNoSourceID)
+		}
+		r.SourceID = "999"
+		output, err := r.ToIntermediateSchema()
+		if _, ok := err.(span.Skip); ok {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, output)
+	}
+	if len(result) != 3 {
+		t.Fatalf("got %d records, want 3", len(result))
+	}
+	var cases = []struct {
+		record int
+		field  string
+		value  any
+		result any
+	}{
+		{0, "ID", result[0].ID, "ai-999-YXJ0LTE"},
+		{0, "Genre", result[0].Genre, "article"},
+		{0, "ArticleTitle", result[0].ArticleTitle, "A title spanning two lines"},
+		{0, "JournalTitle", result[0].JournalTitle, "Journal of Examples"},
+		{0, "ISSN", result[0].ISSN, []string{"1234-5678", "1234-5679"}},
+		{0, "Pages", result[0].Pages, "45-67"},
+		{0, "RawDate", result[0].RawDate, "2019-05-12"},
+		{0, "DOI", result[0].DOI, "10.1000/xyz.123"},
+		{0, "Languages", result[0].Languages, []string{"eng"}},
+		{0, "Subjects", result[0].Subjects, []string{"first", "second"}},
+		{1, "Genre", result[1].Genre, "book"},
+		{1, "BookTitle", result[1].BookTitle, "A book"},
+		{1, "ISBN", result[1].ISBN, []string{"9783161484100"}},
+		{1, "Publishers", result[1].Publishers, []string{"Example Press"}},
+		{2, "Genre", result[2].Genre, "unknown"},
+		{2, "ArticleTitle", result[2].ArticleTitle, "Journal of Examples"},
+		{2, "JournalTitle", result[2].JournalTitle, "Journal of Examples"},
+		{2, "ISSN", result[2].ISSN, []string{"1234-5679"}},
+		{2, "ISBN", result[2].ISBN, []string(nil)},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.value, c.result) {
+			t.Errorf("record %d, %s: got %v, want %v", c.record, c.field, c.value, c.result)
+		}
+	}
+}
@@ -242,5 +242,12 @@ func (r Record) ToIntermediateSchema() (*finc.IntermediateSchema, error) {
 	if pub := r.MustGetFirstDataField("264.b"); pub != "" {
 		output.Publishers = append(output.Publishers, pub)
 	}
+	// Rights, like "Deposit Licence - Keine Bearbeitung", are normalized on
+	// export, refs. finc.NormalizeLicense.
+	for _, rights := range r.MustGetDataFields("540.a") {
+		if rights = strings.TrimSpace(rights); rights != "" {
+			output.License = append(output.License, rights)
+		}
+	}
 	return output, nil
 }
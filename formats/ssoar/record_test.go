@@ -0,0 +1,35 @@
+package ssoar
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+)
+
+func TestRecordLicense(t *testing.T) {
+	var r Record
+	if err := xml.Unmarshal([]byte(`<record>
+		<header><identifier>oai:gesis.izsoz.de:document/12345</identifier></header>
+		<metadata><record>
+			<leader>00000nab a2200000 u 4500</leader>
+			<datafield tag="245" ind1="0" ind2="0"><subfield code="a">Ein Titel</subfield></datafield>
+			<datafield tag="264" ind1=" " ind2="1"><subfield code="c">2019</subfield></datafield>
+			<datafield tag="540" ind1=" " ind2=" "><subfield code="a">Deposit Licence - Keine Bearbeitung</subfield></datafield>
+		</record></metadata>
+	</record>`), &r); err != nil {
+		t.Fatal(err)
+	}
+	output, err := r.ToIntermediateSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Deposit Licence - Keine Bearbeitung"}
+	if !reflect.DeepEqual(output.License, want) {
+		t.Errorf("got %v, want %v", output.License, want)
+	}
+	if got := finc.NormalizeLicense(output.License[0]); got != "SSOAR-DEPOSIT" {
+		t.Errorf("got %q, want %q", got, "SSOAR-DEPOSIT")
+	}
+}
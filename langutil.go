@@ -36,6 +36,39 @@ func LanguageIdentifier(s string) string {
 	return ""
 }
 
+// iso639Three is the set of ISO 639-3 identifiers.
+var iso639Three = func() map[string]bool {
+	m := make(map[string]bool, len(ISO639NameToThree))
+	for _, v := range ISO639NameToThree {
+		m[v] = true
+	}
+	return m
+}()
+
+// LanguageCode is like LanguageIdentifier, but also accepts ISO 639-3
+// identifiers, like "eng" or "spa", regardless of case, and tags with a
+// region, like "en-US" or "de_DE". Special identifiers for undetermined,
+// multiple, uncoded or no language yield the empty string. Use this for
+// language fields of unknown origin, e.g. in RIS, BibTeX or MARC records.
+func LanguageCode(s string) string {
+	s = strings.TrimSpace(s)
+	v := LanguageIdentifier(s)
+	if v == "" {
+		s = strings.ToLower(s)
+		if k := strings.IndexAny(s, "-_"); k == 2 || k == 3 {
+			s = s[:k]
+		}
+		if v = LanguageIdentifier(s); v == "" && iso639Three[s] {
+			v = s
+		}
+	}
+	switch v {
+	case "und", "mul", "mis", "zxx":
+		return ""
+	}
+	return v
+}
+
 // ISO639OneToThree maps 639-1 identifier (two letters) (if there is one) to a
 // three-letter 639-3 identifier.
 var ISO639OneToThree = map[string]string{
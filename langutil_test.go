@@ -57,3 +57,33 @@ func TestLanguageIdentifier(t *testing.T) {
 		})
 	}
 }
+
+func TestLanguageCode(t *testing.T) {
+	var cases = []struct {
+		in  string
+		out string
+	}{
+		{"German", "deu"},
+		{"de", "deu"},
+		{"DE", "deu"},
+		{"ger", "deu"},
+		{"deu", "deu"},
+		{"eng", "eng"},
+		{"Spa", "spa"},
+		{"en-US", "eng"},
+		{"de_DE", "deu"},
+		{"serbo-croatian", "hbs"},
+		{"und", ""},
+		{"Multiple languages", ""},
+		{"xyz", ""},
+		{"Deutsch", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			result := LanguageCode(c.in)
+			if result != c.out {
+				t.Errorf("got %v, want %v", result, c.out)
+			}
+		})
+	}
+}
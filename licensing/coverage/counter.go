@@ -0,0 +1,115 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/solrutil"
+)
+
+// IndexCounter counts documents in a SOLR index with a facet query on the
+// publication year.
+type IndexCounter struct {
+	Index     solrutil.Index
+	ISSNField string // Defaults to "issn".
+	YearField string // Defaults to "publishDateSort".
+}
+
+// YearCounts runs a single facet query for all ISSN.
+func (c *IndexCounter) YearCounts(issns []string) (map[int]int, error) {
+	var (
+		issnField = c.ISSNField
+		yearField = c.YearField
+		quoted    = make([]string, len(issns))
+	)
+	if issnField == "" {
+		issnField = "issn"
+	}
+	if yearField == "" {
+		yearField = "publishDateSort"
+	}
+	for i, issn := range issns {
+		quoted[i] = strconv.Quote(issn)
+	}
+	query := fmt.Sprintf("%s:(%s)", issnField, strings.Join(quoted, " OR "))
+	resp, err := c.Index.FacetQuery(query, yearField)
+	if err != nil {
+		return nil, err
+	}
+	fmap, err := resp.Facets()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[int]int)
+	for k, v := range fmap.Nonzero() {
+		if y, err := strconv.Atoi(k); err == nil {
+			counts[y] += v
+		}
+	}
+	return counts, nil
+}
+
+// FileCounter counts documents from an intermediate schema file. Only the
+// publication year and the ISSN of each document are kept.
+type FileCounter struct {
+	years []int16            // Publication year per document.
+	docs  map[string][]int32 // ISSN to document offsets.
+}
+
+// ReadFile reads intermediate schema documents, one per line.
+func ReadFile(r io.Reader) (*FileCounter, error) {
+	var (
+		c  = &FileCounter{docs: make(map[string][]int32)}
+		br = bufio.NewReader(r)
+	)
+	for {
+		b, err := br.ReadBytes('\n')
+		if err == io.EOF && len(b) == 0 {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(strings.TrimSpace(string(b))) == 0 {
+			continue
+		}
+		var is finc.IntermediateSchema
+		if err := json.Unmarshal(b, &is); err != nil {
+			return nil, err
+		}
+		if is.Date.IsZero() {
+			continue
+		}
+		offset := int32(len(c.years))
+		c.years = append(c.years, int16(is.Date.Year()))
+		for _, issn := range is.ISSNList() {
+			issn = strings.ToUpper(issn)
+			c.docs[issn] = append(c.docs[issn], offset)
+		}
+	}
+	return c, nil
+}
+
+// YearCounts counts the documents with any of the given ISSN.
+func (c *FileCounter) YearCounts(issns []string) (map[int]int, error) {
+	var (
+		counts = make(map[int]int)
+		seen   = make(map[int32]struct{})
+	)
+	for _, issn := range issns {
+		for _, offset := range c.docs[strings.ToUpper(issn)] {
+			if _, ok := seen[offset]; ok {
+				continue
+			}
+			seen[offset] = struct{}{}
+			counts[int(c.years[offset])]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,163 @@
+// Package coverage compares the coverage of KBART holdings entries with the
+// documents actually available, either in a SOLR index or in an intermediate
+// schema file. For each entry and publication year, documents are counted and
+// classified as inside or outside the licensed coverage window. Years within
+// the window without any document are reported as missing.
+//
+// Example:
+//
+//	counter := &coverage.IndexCounter{Index: solrutil.Index{Server: server}}
+//	reports, err := coverage.Run(holdings, counter, time.Now())
+//	...
+//	err = coverage.WriteTSV(os.Stdout, reports)
+package coverage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/miku/span/licensing"
+)
+
+// Status of a publication year relative to the coverage window of an entry.
+type Status string
+
+const (
+	// StatusInside marks documents, that are covered by the entry.
+	StatusInside Status = "inside"
+	// StatusOutside marks documents, that are not covered by the entry,
+	// e.g. older than the first licensed issue or still under embargo.
+	StatusOutside Status = "outside"
+	// StatusMissing marks covered years without any document.
+	StatusMissing Status = "missing"
+)
+
+// Counter counts documents per publication year for a serial, identified by
+// any of its ISSN. A document with several matching ISSN is counted once.
+type Counter interface {
+	YearCounts(issns []string) (map[int]int, error)
+}
+
+// Year is the number of documents in a single publication year.
+type Year struct {
+	Year   int    `json:"year"`
+	Count  int    `json:"count"`
+	Status Status `json:"status"`
+}
+
+// Report summarizes the coverage of a single KBART entry.
+type Report struct {
+	Title     string   `json:"title"`
+	ISSN      []string `json:"issn"`
+	FirstDate string   `json:"first_date,omitempty"`
+	LastDate  string   `json:"last_date,omitempty"`
+	Embargo   string   `json:"embargo,omitempty"`
+	Inside    int      `json:"inside"`
+	Outside   int      `json:"outside"`
+	Missing   []int    `json:"missing,omitempty"`
+	Years     []Year   `json:"years,omitempty"`
+}
+
+// window returns the range of years, that are relevant for an entry, given
+// the years with documents. Open boundaries are taken from the documents and
+// the current year.
+func window(entry *licensing.Entry, years []int, now time.Time) (first, last int) {
+	first, last = now.Year(), now.Year()
+	if len(years) > 0 {
+		first = years[0]
+	}
+	if t, _, err := licensing.ParseDate(entry.FirstIssueDate); err == nil {
+		first = t.Year()
+	}
+	if t, _, err := licensing.ParseDate(entry.LastIssueDate); err == nil && t.Year() < last {
+		last = t.Year()
+	}
+	return first, last
+}
+
+// covers returns true, if an entry covers a publication year. The embargo is
+// evaluated relative to now, not to the current time, like Entry.CoversDate
+// does.
+func covers(entry *licensing.Entry, embargo licensing.Embargo, year int, now time.Time) bool {
+	if entry.CoversDate(fmt.Sprintf("%04d", year)) != nil {
+		return false
+	}
+	t := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return embargo.CompatibleToGranularity(t, licensing.GranularityYear, now) == nil
+}
+
+// Compare classifies the per year document counts for an entry. Coverage
+// windows without an end are considered up to the year of now, embargoes are
+// evaluated relative to now.
+func Compare(entry licensing.Entry, counts map[int]int, now time.Time) Report {
+	r := Report{
+		Title:     entry.PublicationTitle,
+		ISSN:      entry.ISSNList(),
+		FirstDate: entry.FirstIssueDate,
+		LastDate:  entry.LastIssueDate,
+		Embargo:   entry.Embargo,
+	}
+	var years []int
+	for y, c := range counts {
+		if c > 0 {
+			years = append(years, y)
+		}
+	}
+	slices.Sort(years)
+	// The embargo is checked separately, relative to now.
+	embargo := licensing.Embargo(entry.Embargo)
+	entry.Embargo = ""
+	first, last := window(&entry, years, now)
+	for y := first; y <= last; y++ {
+		if counts[y] == 0 && covers(&entry, embargo, y, now) {
+			years = append(years, y)
+		}
+	}
+	slices.Sort(years)
+	for _, y := range slices.Compact(years) {
+		var (
+			count   = counts[y]
+			covered = covers(&entry, embargo, y, now)
+		)
+		switch {
+		case count == 0:
+			r.Missing = append(r.Missing, y)
+			r.Years = append(r.Years, Year{Year: y, Status: StatusMissing})
+		case covered:
+			r.Inside += count
+			r.Years = append(r.Years, Year{Year: y, Count: count, Status: StatusInside})
+		default:
+			r.Outside += count
+			r.Years = append(r.Years, Year{Year: y, Count: count, Status: StatusOutside})
+		}
+	}
+	return r
+}
+
+// Run compares all entries with an ISSN against the documents of a counter.
+// The counter is asked only once for entries with the same ISSN.
+func Run(entries []licensing.Entry, c Counter, now time.Time) ([]Report, error) {
+	var (
+		reports []Report
+		cache   = make(map[string]map[int]int)
+	)
+	for _, entry := range entries {
+		issns := entry.ISSNList()
+		if len(issns) == 0 {
+			continue
+		}
+		key := strings.Join(issns, " ")
+		counts, ok := cache[key]
+		if !ok {
+			var err error
+			if counts, err = c.YearCounts(issns); err != nil {
+				return nil, err
+			}
+			cache[key] = counts
+		}
+		reports = append(reports, Compare(entry, counts, now))
+	}
+	return reports, nil
+}
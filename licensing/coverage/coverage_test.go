@@ -0,0 +1,109 @@
+package coverage
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miku/span/licensing"
+)
+
+func TestRun(t *testing.T) {
+	counter, err := ReadFile(strings.NewReader(`{"rft.issn": ["1050-124X"], "x.date": "1999-01-01T00:00:00Z"}
+{"rft.issn": ["1050-124X"], "rft.eissn": ["0028-0844"], "x.date": "2001-01-01T00:00:00Z"}
+{"rft.eissn": ["0028-0844"], "x.date": "2001-06-01T00:00:00Z"}
+
+{"rft.issn": ["1050-124X"], "x.date": "2024-01-01T00:00:00Z"}
+{"rft.issn": ["2222-2222"]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := []licensing.Entry{
+		{
+			PublicationTitle: "Journal of Examples",
+			PrintIdentifier:  "1050-124X",
+			OnlineIdentifier: "0028-0844",
+			FirstIssueDate:   "2000",
+			LastIssueDate:    "2003",
+		},
+		{PublicationTitle: "Some Book", PrintIdentifier: "9783658108380"},
+		{PublicationTitle: "Not Indexed", PrintIdentifier: "1234-5679"},
+	}
+	reports, err := Run(entries, counter, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+	r := reports[0]
+	if r.Inside != 2 || r.Outside != 2 {
+		t.Errorf("got %d inside, %d outside, want 2, 2", r.Inside, r.Outside)
+	}
+	if want := []int{2000, 2002, 2003}; !reflect.DeepEqual(r.Missing, want) {
+		t.Errorf("got missing %v, want %v", r.Missing, want)
+	}
+	want := []Year{
+		{1999, 1, StatusOutside},
+		{2000, 0, StatusMissing},
+		{2001, 2, StatusInside},
+		{2002, 0, StatusMissing},
+		{2003, 0, StatusMissing},
+		{2024, 1, StatusOutside},
+	}
+	if !reflect.DeepEqual(r.Years, want) {
+		t.Errorf("got years %v, want %v", r.Years, want)
+	}
+	// Without documents and dates, only the current year is checked.
+	if r := reports[1]; r.Inside != 0 || len(r.Years) != 1 || r.Years[0].Status != StatusMissing {
+		t.Errorf("got %+v", r)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTSV(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("got %d lines, want 8", len(lines))
+	}
+	if want := "Journal of Examples\t0028-0844 1050-124X\t2000\t2003\t\t2001\tinside\t2"; lines[3] != want {
+		t.Errorf("got %q, want %q", lines[3], want)
+	}
+	buf.Reset()
+	if err := WriteXLSX(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Errorf("invalid workbook: %v", err)
+	}
+}
+
+func TestCompareEmbargo(t *testing.T) {
+	var (
+		entry = licensing.Entry{
+			PublicationTitle: "Journal of Examples",
+			PrintIdentifier:  "1050-124X",
+			FirstIssueDate:   "2019",
+			Embargo:          "P1Y",
+		}
+		counts = map[int]int{2019: 1, 2021: 1}
+		now    = time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	)
+	r := Compare(entry, counts, now)
+	want := []Year{
+		{2019, 1, StatusInside},
+		{2020, 0, StatusMissing},
+		{2021, 1, StatusOutside},
+	}
+	if !reflect.DeepEqual(r.Years, want) {
+		t.Errorf("got years %v, want %v", r.Years, want)
+	}
+	if r.Embargo != "P1Y" {
+		t.Errorf("got embargo %q, want P1Y", r.Embargo)
+	}
+}
@@ -0,0 +1,140 @@
+package coverage
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// Header lists the columns of the tabular report, one row per entry and year.
+var Header = []string{"title", "issn", "first_date", "last_date", "embargo", "year", "status", "count"}
+
+// Rows returns the tabular report, one row per entry and year. Entries
+// without any year are listed with empty year and status.
+func Rows(reports []Report) [][]string {
+	var rows [][]string
+	for _, r := range reports {
+		prefix := []string{r.Title, strings.Join(r.ISSN, " "), r.FirstDate, r.LastDate, r.Embargo}
+		if len(r.Years) == 0 {
+			rows = append(rows, append(prefix, "", "", "0"))
+			continue
+		}
+		for _, y := range r.Years {
+			row := append(append([]string{}, prefix...), strconv.Itoa(y.Year), string(y.Status), strconv.Itoa(y.Count))
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// numericColumns are the columns of the tabular report holding numbers.
+var numericColumns = map[int]bool{5: true, 7: true}
+
+// WriteTSV writes the tabular report as tab separated values with a header.
+func WriteTSV(w io.Writer, reports []Report) error {
+	var (
+		bw       = bufio.NewWriter(w)
+		replacer = strings.NewReplacer("\t", " ", "\n", " ")
+	)
+	for _, row := range append([][]string{Header}, Rows(reports)...) {
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteByte('\t')
+			}
+			bw.WriteString(replacer.Replace(v))
+		}
+		if err := bw.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteJSON writes one JSON document per entry.
+func WriteJSON(w io.Writer, reports []Report) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, r := range reports {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// xlsxFiles are the static parts of a workbook with a single sheet.
+var xlsxFiles = []struct {
+	name, content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Coverage" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+// WriteXLSX writes the tabular report as a workbook with a single sheet.
+// Years and counts are stored as numbers, everything else as inline strings.
+func WriteXLSX(w io.Writer, reports []Report) error {
+	zw := zip.NewWriter(w)
+	for _, f := range xlsxFiles {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(fw)
+	io.WriteString(bw, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n")
+	io.WriteString(bw, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range append([][]string{Header}, Rows(reports)...) {
+		fmt.Fprintf(bw, `<row r="%d">`, i+1)
+		for j, v := range row {
+			if v == "" {
+				continue
+			}
+			ref := fmt.Sprintf("%c%d", 'A'+j, i+1)
+			if i > 0 && numericColumns[j] {
+				fmt.Fprintf(bw, `<c r="%s"><v>%s</v></c>`, ref, v)
+				continue
+			}
+			fmt.Fprintf(bw, `<c r="%s" t="inlineStr"><is><t>`, ref)
+			if err := xml.EscapeText(bw, []byte(v)); err != nil {
+				return err
+			}
+			io.WriteString(bw, `</t></is></c>`)
+		}
+		io.WriteString(bw, `</row>`)
+	}
+	io.WriteString(bw, `</sheetData></worksheet>`)
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
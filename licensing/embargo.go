@@ -89,26 +89,64 @@ var (
 // calendar years of content are available, except for the most current 30 days.
 type Embargo string
 
+// Split separates an embargo into the statement for the beginning and the
+// statement for the end of a coverage range, e.g. "R10Y;P30D" yields "R10Y"
+// and "P30D". A single statement is returned in its position, the other one
+// is empty. More than two statements or two statements of the same type are
+// invalid.
+func (embargo Embargo) Split() (begin, end Embargo, err error) {
+	e := strings.TrimSpace(string(embargo))
+	if len(e) == 0 {
+		return
+	}
+	parts := strings.Split(e, ";")
+	if len(parts) > 2 {
+		return "", "", ErrInvalidEmbargo
+	}
+	for _, p := range parts {
+		s := Embargo(strings.TrimSpace(p))
+		switch {
+		case s.AccessBeginsAtWall() && begin == "":
+			begin = s
+		case s.AccessEndsAtWall() && end == "":
+			end = s
+		default:
+			return "", "", ErrInvalidEmbargo
+		}
+	}
+	return begin, end, nil
+}
+
+// parse returns length and unit of a single embargo statement.
+func (embargo Embargo) parse() (length int, unit string, err error) {
+	parts := embargoPattern.FindStringSubmatch(strings.TrimSpace(string(embargo)))
+	if len(parts) < 4 {
+		return 0, "", ErrInvalidEmbargo
+	}
+	if length, err = strconv.Atoi(parts[2]); err != nil {
+		return 0, "", ErrInvalidEmbargo
+	}
+	return length, parts[3], nil
+}
+
 // Duration converts embargo like P12M, P1M, R10Y into a time.Duration. This
 // duration will be positive. Time differences will have small shifts due to a
-// month and a year being a fixed number of hours.
+// month and a year being a fixed number of hours, use MovingWalls for calendar
+// accurate walls. A combined embargo like "R10Y;P30D" has no single duration,
+// use Split to get to the statements.
 func (embargo Embargo) Duration() (dur time.Duration, err error) {
 	e := strings.TrimSpace(string(embargo))
 	if len(e) == 0 {
 		return
 	}
-	parts := embargoPattern.FindStringSubmatch(e)
-	if len(parts) == 0 {
-		return dur, ErrInvalidEmbargo
-	}
-	if len(parts) < 4 {
+	if strings.Contains(e, ";") {
 		return dur, ErrInvalidEmbargo
 	}
-	i, err := strconv.Atoi(parts[2])
+	i, unit, err := embargo.parse()
 	if err != nil {
-		return dur, ErrInvalidEmbargo
+		return dur, err
 	}
-	switch parts[3] {
+	switch unit {
 	case "D":
 		return time.Duration(i) * Day, nil
 	case "M":
@@ -120,6 +158,77 @@ func (embargo Embargo) Duration() (dur time.Duration, err error) {
 	}
 }
 
+// granularity returns the granularity of the embargo unit, which is also how
+// often the moving wall moves.
+func (embargo Embargo) granularity() (DateGranularity, error) {
+	_, unit, err := embargo.parse()
+	if err != nil {
+		return GranularityDay, err
+	}
+	switch unit {
+	case "D":
+		return GranularityDay, nil
+	case "M":
+		return GranularityMonth, nil
+	case "Y":
+		return GranularityYear, nil
+	default:
+		return GranularityDay, ErrInvalidEmbargo
+	}
+}
+
+// wall returns the moving wall of a single embargo statement relative to a
+// given date. The wall moves in calendar units: the current year, month or
+// day counts as the first unit, so "P1Y" relative to 2024-06-10 is
+// 2024-01-01 (all content except the current calendar year) and "R2Y" is
+// 2023-01-01 (previous and current calendar years).
+func (embargo Embargo) wall(relative time.Time) (time.Time, error) {
+	i, _, err := embargo.parse()
+	if err != nil {
+		return relative, err
+	}
+	g, err := embargo.granularity()
+	if err != nil {
+		return relative, err
+	}
+	start := time.Date(relative.Year(), relative.Month(), relative.Day(), 0, 0, 0, 0, time.UTC)
+	start = truncate(start, g)
+	if i == 0 {
+		return start, nil
+	}
+	switch g {
+	case GranularityYear:
+		return start.AddDate(-(i - 1), 0, 0), nil
+	case GranularityMonth:
+		return start.AddDate(0, -(i - 1), 0), nil
+	default:
+		return start.AddDate(0, 0, -(i - 1)), nil
+	}
+}
+
+// MovingWalls returns the moving walls of this embargo relative to a given
+// date. Access begins at the begin wall (inclusive) and ends at the end wall
+// (exclusive). The walls move in whole calendar units, as described in the
+// KBART recommendation. A zero time is returned for a wall that is not
+// defined by this embargo.
+func (embargo Embargo) MovingWalls(relative time.Time) (begin, end time.Time, err error) {
+	b, e, err := embargo.Split()
+	if err != nil {
+		return begin, end, err
+	}
+	if b != "" {
+		if begin, err = b.wall(relative); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	if e != "" {
+		if end, err = e.wall(relative); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return begin, end, nil
+}
+
 // AccessBeginsAtWall returns true, if access begins at the moving wall.
 func (embargo Embargo) AccessBeginsAtWall() bool {
 	return strings.HasPrefix(strings.TrimSpace(string(embargo)), "R")
@@ -135,17 +244,26 @@ func (embargo Embargo) Compatible(t time.Time) error {
 	return embargo.CompatibleTo(t, time.Now())
 }
 
-// CompatibleTo returns true, if the given date in validated by this embargo relative to another date.
+// CompatibleTo returns true, if the given date in validated by this embargo
+// relative to another date. For combined embargoes, both the wall at the
+// beginning and the wall at the end of the coverage range are checked.
 func (embargo Embargo) CompatibleTo(t time.Time, relative time.Time) error {
-	dur, err := embargo.Duration()
+	return embargo.CompatibleToGranularity(t, GranularityDay, relative)
+}
+
+// CompatibleToGranularity is like CompatibleTo, but takes into account that
+// the given date may only be known to the year or month. A date is
+// compatible, if any part of the period it denotes lies between the walls,
+// e.g. a record from "2023" is compatible with a "R1Y" wall at 2023-07-01.
+func (embargo Embargo) CompatibleToGranularity(t time.Time, g DateGranularity, relative time.Time) error {
+	begin, end, err := embargo.MovingWalls(relative)
 	if err != nil {
 		return err
 	}
-	wall := relative.Add(-dur)
-	if embargo.AccessBeginsAtWall() && t.Before(wall) {
+	if !begin.IsZero() && t.Before(truncate(begin, g)) {
 		return ErrBeforeMovingWall
 	}
-	if embargo.AccessEndsAtWall() && t.After(wall) {
+	if !end.IsZero() && !t.Before(end) {
 		return ErrAfterMovingWall
 	}
 	return nil
@@ -33,6 +33,7 @@ func TestEmbargoDuration(t *testing.T) {
 		{embargo: Embargo("R1M"), dur: mustParseDuration("730h"), err: nil},
 		{embargo: Embargo("RaY"), dur: 0, err: ErrInvalidEmbargo},
 		{embargo: Embargo("RRR"), dur: 0, err: ErrInvalidEmbargo},
+		{embargo: Embargo("R10Y;P30D"), dur: 0, err: ErrInvalidEmbargo},
 	}
 	for _, c := range cases {
 		t.Run(string(c.embargo), func(t *testing.T) {
@@ -79,10 +80,66 @@ func TestEmbargoCompatible(t *testing.T) {
 			mustParseTime("2006-01-02", "2000-01-01"),
 			mustParseTime("2006-01-02", "2001-01-01"),
 			nil},
-		{"R1Y access begin", Embargo("R1Y"),
+		{"R1Y is the current calendar year only", Embargo("R1Y"),
 			mustParseTime("2006-01-02", "2000-01-03"),
 			mustParseTime("2006-01-02", "2001-01-01"),
+			ErrBeforeMovingWall},
+		{"R1Y access begin", Embargo("R1Y"),
+			mustParseTime("2006-01-02", "2001-01-01"),
+			mustParseTime("2006-01-02", "2001-12-31"),
+			nil},
+		{"R2Y previous and current calendar year", Embargo("R2Y"),
+			mustParseTime("2006-01-02", "2000-01-01"),
+			mustParseTime("2006-01-02", "2001-12-31"),
+			nil},
+		{"R2Y before previous calendar year", Embargo("R2Y"),
+			mustParseTime("2006-01-02", "1999-12-31"),
+			mustParseTime("2006-01-02", "2001-01-01"),
+			ErrBeforeMovingWall},
+		{"P1Y last day of previous calendar year", Embargo("P1Y"),
+			mustParseTime("2006-01-02", "2000-12-31"),
+			mustParseTime("2006-01-02", "2001-01-01"),
 			nil},
+		{"P1Y first day of current calendar year", Embargo("P1Y"),
+			mustParseTime("2006-01-02", "2001-01-01"),
+			mustParseTime("2006-01-02", "2001-12-31"),
+			ErrAfterMovingWall},
+		{"P1M previous calendar month", Embargo("P1M"),
+			mustParseTime("2006-01-02", "2001-02-28"),
+			mustParseTime("2006-01-02", "2001-03-01"),
+			nil},
+		{"P1M current calendar month", Embargo("P1M"),
+			mustParseTime("2006-01-02", "2001-03-01"),
+			mustParseTime("2006-01-02", "2001-03-31"),
+			ErrAfterMovingWall},
+		{"P6M except the past six calendar months", Embargo("P6M"),
+			mustParseTime("2006-01-02", "2001-01-15"),
+			mustParseTime("2006-01-02", "2001-06-10"),
+			ErrAfterMovingWall},
+		{"P6M before the past six calendar months", Embargo("P6M"),
+			mustParseTime("2006-01-02", "2000-12-31"),
+			mustParseTime("2006-01-02", "2001-06-10"),
+			nil},
+		{"R10Y;P30D inside both walls", Embargo("R10Y;P30D"),
+			mustParseTime("2006-01-02", "2005-06-01"),
+			mustParseTime("2006-01-02", "2010-01-01"),
+			nil},
+		{"R10Y;P30D before begin wall", Embargo("R10Y;P30D"),
+			mustParseTime("2006-01-02", "1999-12-31"),
+			mustParseTime("2006-01-02", "2010-01-01"),
+			ErrBeforeMovingWall},
+		{"R10Y;P30D after end wall", Embargo("R10Y;P30D"),
+			mustParseTime("2006-01-02", "2009-12-15"),
+			mustParseTime("2006-01-02", "2010-01-01"),
+			ErrAfterMovingWall},
+		{"P30D;R10Y order does not matter", Embargo("P30D;R10Y"),
+			mustParseTime("2006-01-02", "2009-12-15"),
+			mustParseTime("2006-01-02", "2010-01-01"),
+			ErrAfterMovingWall},
+		{"R10Y;R1Y is invalid", Embargo("R10Y;R1Y"),
+			mustParseTime("2006-01-02", "2009-12-15"),
+			mustParseTime("2006-01-02", "2010-01-01"),
+			ErrInvalidEmbargo},
 	}
 	for _, c := range cases {
 		t.Run(c.about, func(t *testing.T) {
@@ -94,6 +151,107 @@ func TestEmbargoCompatible(t *testing.T) {
 	}
 }
 
+func TestEmbargoMovingWalls(t *testing.T) {
+	var cases = []struct {
+		embargo Embargo
+		rel     time.Time
+		begin   time.Time
+		end     time.Time
+		err     error
+	}{
+		{Embargo(""), mustParseTime("2006-01-02", "2024-06-10"), time.Time{}, time.Time{}, nil},
+		{Embargo("P1Y"), mustParseTime("2006-01-02", "2024-06-10"),
+			time.Time{}, mustParseTime("2006-01-02", "2024-01-01"), nil},
+		{Embargo("R2Y"), mustParseTime("2006-01-02", "2024-06-10"),
+			mustParseTime("2006-01-02", "2023-01-01"), time.Time{}, nil},
+		{Embargo("P6M"), mustParseTime("2006-01-02", "2024-06-10"),
+			time.Time{}, mustParseTime("2006-01-02", "2024-01-01"), nil},
+		{Embargo("P12M"), mustParseTime("2006-01-02", "2024-03-31"),
+			time.Time{}, mustParseTime("2006-01-02", "2023-04-01"), nil},
+		{Embargo("R180D"), mustParseTime("2006-01-02", "2024-06-10"),
+			mustParseTime("2006-01-02", "2023-12-14"), time.Time{}, nil},
+		{Embargo("R10Y;P30D"), mustParseTime("2006-01-02", "2024-06-10"),
+			mustParseTime("2006-01-02", "2015-01-01"), mustParseTime("2006-01-02", "2024-05-12"), nil},
+		{Embargo("R1X"), mustParseTime("2006-01-02", "2024-06-10"), time.Time{}, time.Time{}, ErrInvalidEmbargo},
+	}
+	for _, c := range cases {
+		t.Run(string(c.embargo), func(t *testing.T) {
+			begin, end, err := c.embargo.MovingWalls(c.rel)
+			if err != c.err {
+				t.Errorf("MovingWalls: got %v, want %v", err, c.err)
+			}
+			if !begin.Equal(c.begin) || !end.Equal(c.end) {
+				t.Errorf("MovingWalls: got %v %v, want %v %v", begin, end, c.begin, c.end)
+			}
+		})
+	}
+}
+
+func TestEmbargoCompatibleToGranularity(t *testing.T) {
+	var cases = []struct {
+		about   string
+		embargo Embargo
+		t       time.Time
+		g       DateGranularity
+		rel     time.Time
+		err     error
+	}{
+		{"year record overlaps begin wall", Embargo("R180D"),
+			mustParseTime("2006", "2023"), GranularityYear,
+			mustParseTime("2006-01-02", "2024-06-10"), nil},
+		{"day record before begin wall", Embargo("R180D"),
+			mustParseTime("2006-01-02", "2023-12-13"), GranularityDay,
+			mustParseTime("2006-01-02", "2024-06-10"), ErrBeforeMovingWall},
+		{"month record overlaps begin wall", Embargo("R180D"),
+			mustParseTime("2006-01", "2023-12"), GranularityMonth,
+			mustParseTime("2006-01-02", "2024-06-10"), nil},
+		{"year record starts before end wall", Embargo("P6M"),
+			mustParseTime("2006", "2023"), GranularityYear,
+			mustParseTime("2006-01-02", "2024-06-10"), nil},
+		{"year record of current year", Embargo("P1Y"),
+			mustParseTime("2006", "2024"), GranularityYear,
+			mustParseTime("2006-01-02", "2024-06-10"), ErrAfterMovingWall},
+	}
+	for _, c := range cases {
+		t.Run(c.about, func(t *testing.T) {
+			err := c.embargo.CompatibleToGranularity(c.t, c.g, c.rel)
+			if err != c.err {
+				t.Errorf("CompatibleToGranularity(%v, %v, %v): got %v, want %v", c.embargo, c.t, c.rel, err, c.err)
+			}
+		})
+	}
+}
+
+func TestEmbargoSplit(t *testing.T) {
+	var cases = []struct {
+		embargo Embargo
+		begin   Embargo
+		end     Embargo
+		err     error
+	}{
+		{Embargo(""), "", "", nil},
+		{Embargo("R10Y"), "R10Y", "", nil},
+		{Embargo("P30D"), "", "P30D", nil},
+		{Embargo("R10Y;P30D"), "R10Y", "P30D", nil},
+		{Embargo(" R10Y ; P30D "), "R10Y", "P30D", nil},
+		{Embargo("P30D;R10Y"), "R10Y", "P30D", nil},
+		{Embargo("P1Y;P30D"), "", "", ErrInvalidEmbargo},
+		{Embargo("R1Y;P1Y;P30D"), "", "", ErrInvalidEmbargo},
+		{Embargo("X1Y"), "", "", ErrInvalidEmbargo},
+	}
+	for _, c := range cases {
+		t.Run(string(c.embargo), func(t *testing.T) {
+			begin, end, err := c.embargo.Split()
+			if err != c.err {
+				t.Errorf("Split: got %v, want %v", err, c.err)
+			}
+			if begin != c.begin || end != c.end {
+				t.Errorf("Split: got %q %q, want %q %q", begin, end, c.begin, c.end)
+			}
+		})
+	}
+}
+
 func TestEmbargoAccessBeginsAtWall(t *testing.T) {
 	var cases = []struct {
 		e                  Embargo
@@ -151,17 +151,51 @@ func (entry *Entry) ISSNList() []string {
 	return issns.SortedValues()
 }
 
+// IsEbook returns true, if the entry describes a monograph, e.g. as part of an
+// ebook package.
+func (entry *Entry) IsEbook() bool {
+	return strings.EqualFold(strings.TrimSpace(entry.CoverageDepth), "ebook")
+}
+
+// ISBNList returns a list of unique ISBN-13 without hyphens from the
+// identifier fields and the title id.
+func (entry *Entry) ISBNList() []string {
+	isbns := container.NewStringSet()
+	for _, v := range []string{entry.PrintIdentifier, entry.OnlineIdentifier, entry.TitleID} {
+		if s := NormalizeISBN(v); s != "" {
+			isbns.Add(s)
+		}
+	}
+	return isbns.SortedValues()
+}
+
+// DOI returns the lowercased DOI found in title id or online identifier, e.g.
+// "10.1007/978-3-658-10838-0", or the empty string.
+func (entry *Entry) DOI() string {
+	for _, v := range []string{entry.TitleID, entry.OnlineIdentifier} {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "10.") && strings.Contains(v, "/") {
+			return strings.ToLower(v)
+		}
+	}
+	return ""
+}
+
+// ZDB returns the normalized ZDB-ID of the entry, e.g. "1459367-1", or the
+// empty string, refs. NormalizeZDBID.
+func (entry *Entry) ZDB() string {
+	return NormalizeZDBID(entry.ZDBID)
+}
+
 // CoversDate checks whether the given date falls within the entry's date
-// range and satisfies any embargo restrictions.
+// range and satisfies any embargo restrictions, at the granularity of the
+// given date.
 func (entry *Entry) CoversDate(date string) error {
 	t, g, err := parseWithGranularity(date)
 	if err != nil {
 		return err
 	}
-	if err := entry.containsDateTime(t, g); err != nil {
-		return err
-	}
-	return Embargo(entry.Embargo).Compatible(t)
+	return entry.containsDateTime(t, g)
 }
 
 // CoversVolumeIssue checks whether the given volume and issue fall within
@@ -209,9 +243,6 @@ func (entry *Entry) Covers(date, volume, issue string) error {
 	if err := entry.containsDateTime(t, g); err != nil {
 		return err
 	}
-	if err := Embargo(entry.Embargo).Compatible(t); err != nil {
-		return err
-	}
 	return entry.coversVolumeIssue(t, volume, issue)
 }
 
@@ -232,15 +263,7 @@ func (entry *Entry) begin() time.Time {
 
 // beginGranularity returns the begin date with a given granularity.
 func (entry *Entry) beginGranularity(g DateGranularity) time.Time {
-	t := entry.begin()
-	switch g {
-	case GranularityYear:
-		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	case GranularityMonth:
-		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
-	default:
-		return t
-	}
+	return truncate(entry.begin(), g)
 }
 
 // end parses right boundary of license interval, returns a date far in the future
@@ -268,20 +291,12 @@ func (entry *Entry) end() time.Time {
 
 // endGranularity returns the end date with a given granularity.
 func (entry *Entry) endGranularity(g DateGranularity) time.Time {
-	t := entry.end()
-	switch g {
-	case GranularityYear:
-		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
-	case GranularityMonth:
-		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
-	default:
-		return t
-	}
+	return truncate(entry.end(), g)
 }
 
 // containsDateTime returns nil, if the given time lies between this entries'
-// dates. If the given time is the zero value, it will be contained by any
-// interval.
+// dates and is not excluded by a moving wall. If the given time is the zero
+// value, it will be contained by any interval.
 func (entry *Entry) containsDateTime(t time.Time, g DateGranularity) error {
 	if t.IsZero() {
 		return nil
@@ -292,7 +307,20 @@ func (entry *Entry) containsDateTime(t time.Time, g DateGranularity) error {
 	if t.After(entry.endGranularity(g)) {
 		return ErrAfterLastIssueDate
 	}
-	return nil
+	return Embargo(entry.Embargo).CompatibleToGranularity(t, g, time.Now())
+}
+
+// truncate returns the start of the year or month of a given time. Dates with
+// day granularity are returned as is.
+func truncate(t time.Time, g DateGranularity) time.Time {
+	switch g {
+	case GranularityYear:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return t
+	}
 }
 
 // containsDate return nil, if the given date (as string), lies between this
@@ -323,6 +351,99 @@ func FindSerialNumbers(s string) []string {
 	return issnPattern.FindAllString(s, -1)
 }
 
+// NormalizeISBN returns a valid ISBN-10 or ISBN-13 as ISBN-13 without hyphens,
+// e.g. "3-658-10838-X" and "978-3-658-10838-0" both yield "9783658108380".
+// Anything else, including ISBN with an invalid check digit, yields the empty
+// string.
+func NormalizeISBN(s string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(strings.TrimSpace(s)) {
+		switch {
+		case c >= '0' && c <= '9' || c == 'X':
+			b.WriteRune(c)
+		case c == '-' || c == ' ':
+		default:
+			return ""
+		}
+	}
+	digits := b.String()
+	switch len(digits) {
+	case 10:
+		var sum int
+		for i, c := range digits {
+			v := int(c - '0')
+			if c == 'X' {
+				if i != 9 {
+					return ""
+				}
+				v = 10
+			}
+			sum += (10 - i) * v
+		}
+		if sum%11 != 0 {
+			return ""
+		}
+		digits = "978" + digits[:9]
+		return digits + isbn13CheckDigit(digits)
+	case 13:
+		if strings.Contains(digits, "X") || isbn13CheckDigit(digits[:12]) != digits[12:] {
+			return ""
+		}
+		return digits
+	}
+	return ""
+}
+
+// NormalizeZDBID returns a ZDB-ID (Zeitschriftendatenbank) in the form
+// "1459367-1". A "(DE-600)" or "ZDB" prefix, as found in catalog records, is
+// removed. Values with an invalid check digit yield the empty string.
+func NormalizeZDBID(s string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for _, prefix := range []string{"(DE-600)", "ZDB-ID", "ZDB", ":"} {
+		s = strings.TrimSpace(strings.TrimPrefix(s, prefix))
+	}
+	k := strings.LastIndex(s, "-")
+	if k < 1 || k != len(s)-2 {
+		return ""
+	}
+	var sum int
+	for i := k - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return ""
+		}
+		sum += (k - i + 1) * int(c-'0')
+	}
+	check := strconv.Itoa(sum % 11)
+	if check == "10" {
+		check = "X"
+	}
+	if s[k+1:] != check {
+		return ""
+	}
+	return s
+}
+
+// isbn13CheckDigit returns the check digit for the first twelve digits of an
+// ISBN-13.
+func isbn13CheckDigit(s string) string {
+	var sum int
+	for i, c := range s[:12] {
+		if i%2 == 0 {
+			sum += int(c - '0')
+		} else {
+			sum += 3 * int(c-'0')
+		}
+	}
+	return strconv.Itoa((10 - sum%10) % 10)
+}
+
+// ParseDate parses a date in any of the layouts found in holdings files and
+// returns the date along with its granularity, e.g. year for "2006".
+func ParseDate(s string) (time.Time, DateGranularity, error) {
+	return parseWithGranularity(s)
+}
+
 // parseWithGranularity tries to parse a string without explicit layout into a
 // date. If successful, also return the granularity. Any value that is not
 // recorgnized results in an error.
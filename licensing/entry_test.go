@@ -48,6 +48,48 @@ func TestISSNList(t *testing.T) {
 	}
 }
 
+func TestNormalizeISBN(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"978-3-658-10838-0", "9783658108380"},
+		{"9783658108380", "9783658108380"},
+		{"3-658-10838-x", "9783658108380"},
+		{"978-3-658-10838-1", ""},
+		{"3-658-10838-1", ""},
+		{"1234-5678", ""},
+		{"10.1007/978-3-658-10838-0", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if result := NormalizeISBN(c.s); result != c.result {
+			t.Errorf("NormalizeISBN(%q): got %q, want %q", c.s, result, c.result)
+		}
+	}
+}
+
+func TestNormalizeZDBID(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"1459367-1", "1459367-1"},
+		{" (DE-600)1483471-6", "1483471-6"},
+		{"ZDB 2736054-4", "2736054-4"},
+		{"1459367-2", ""},
+		{"14593671", ""},
+		{"n.n.", ""},
+		{"1234-5678", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if result := NormalizeZDBID(c.s); result != c.result {
+			t.Errorf("NormalizeZDBID(%q): got %q, want %q", c.s, result, c.result)
+		}
+	}
+}
+
 func TestContainsDate(t *testing.T) {
 	var cases = []struct {
 		entry Entry
@@ -202,7 +244,7 @@ func TestCovers(t *testing.T) {
 			Entry{
 				FirstIssueDate: "2000-01-01",
 				Embargo:        "P1Y",
-			}, time.Now().Add(-4000 * time.Hour).Format("2006-01-02"), "", "", ErrAfterMovingWall,
+			}, time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), "", "", ErrAfterMovingWall,
 		},
 		{
 			"date ok and moving wall fine",
@@ -211,6 +253,41 @@ func TestCovers(t *testing.T) {
 				Embargo:        "P1Y",
 			}, time.Now().Add(-10000 * time.Hour).Format("2006-01-02"), "", "", nil,
 		},
+		{
+			"year granularity, current calendar year is embargoed",
+			Entry{
+				FirstIssueDate: "2000",
+				Embargo:        "P1Y",
+			}, time.Now().Format("2006"), "", "", ErrAfterMovingWall,
+		},
+		{
+			"year granularity, previous calendar year is fine",
+			Entry{
+				FirstIssueDate: "2000",
+				Embargo:        "P1Y",
+			}, time.Now().AddDate(-1, 0, 0).Format("2006"), "", "", nil,
+		},
+		{
+			"combined embargo, inside both walls",
+			Entry{
+				FirstIssueDate: "1900",
+				Embargo:        "R10Y;P30D",
+			}, time.Now().AddDate(-5, 0, 0).Format("2006-01-02"), "", "", nil,
+		},
+		{
+			"combined embargo, begin wall hit",
+			Entry{
+				FirstIssueDate: "1900",
+				Embargo:        "R10Y;P30D",
+			}, time.Now().AddDate(-11, 0, 0).Format("2006-01-02"), "", "", ErrBeforeMovingWall,
+		},
+		{
+			"combined embargo, end wall hit",
+			Entry{
+				FirstIssueDate: "1900",
+				Embargo:        "R10Y;P30D",
+			}, time.Now().AddDate(0, 0, -7).Format("2006-01-02"), "", "", ErrAfterMovingWall,
+		},
 		{
 			"date ok, first volume after record volume",
 			Entry{FirstIssueDate: "2000", FirstVolume: "6"}, "2001-05-05", "4", "", nil,
@@ -364,7 +441,7 @@ func TestCoversDate(t *testing.T) {
 			Entry{FirstIssueDate: "1990-01-01", LastIssueDate: "2008-01-01"}, "2008-02", ErrAfterLastIssueDate},
 		{"embargo rejects recent date",
 			Entry{FirstIssueDate: "2000-01-01", Embargo: "P1Y"},
-			time.Now().Add(-168 * time.Hour).Format("2006-01-02"), ErrAfterMovingWall},
+			time.Date(time.Now().Year(), 1, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02"), ErrAfterMovingWall},
 		{"embargo allows old date",
 			Entry{FirstIssueDate: "2000-01-01", Embargo: "P1Y"},
 			time.Now().Add(-10000 * time.Hour).Format("2006-01-02"), nil},
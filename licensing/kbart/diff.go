@@ -0,0 +1,176 @@
+package kbart
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/miku/span/licensing"
+)
+
+// ChangeKind describes, how a title changed between two versions of a
+// holdings file.
+type ChangeKind string
+
+const (
+	// Added titles are only found in the new version.
+	Added ChangeKind = "added"
+	// Removed titles are only found in the old version.
+	Removed ChangeKind = "removed"
+	// CoverageChanged titles have different date, volume or issue ranges.
+	CoverageChanged ChangeKind = "coverage"
+	// EmbargoChanged titles have a different embargo.
+	EmbargoChanged ChangeKind = "embargo"
+)
+
+// Change is a difference for a single title. Key is the title_id or, if the
+// title has no id, an ISSN. Old and New contain the coverage or embargo
+// before and after the change.
+type Change struct {
+	Kind  ChangeKind `json:"kind"`
+	Key   string     `json:"key"`
+	Title string     `json:"title"`
+	ISSN  []string   `json:"issn,omitempty"`
+	Old   string     `json:"old,omitempty"`
+	New   string     `json:"new,omitempty"`
+}
+
+// title groups the rows of a holdings file, that belong to the same title.
+// Some files use multiple rows for a title with gaps in coverage.
+type title struct {
+	key     string
+	entries []licensing.Entry
+	issns   []string
+}
+
+// coverage summarizes the date, volume and issue ranges of a title.
+func (t *title) coverage() string {
+	var ranges []string
+	for _, e := range t.entries {
+		ranges = append(ranges, fmt.Sprintf("%s/%s/%s-%s/%s/%s",
+			e.FirstIssueDate, e.FirstVolume, e.FirstIssue,
+			e.LastIssueDate, e.LastVolume, e.LastIssue))
+	}
+	slices.Sort(ranges)
+	return strings.Join(slices.Compact(ranges), "; ")
+}
+
+// embargo summarizes the embargo of a title.
+func (t *title) embargo() string {
+	var values []string
+	for _, e := range t.entries {
+		values = append(values, strings.TrimSpace(e.Embargo))
+	}
+	slices.Sort(values)
+	return strings.Join(slices.Compact(values), "; ")
+}
+
+// change creates a change for a title.
+func (t *title) change(kind ChangeKind, before, after string) Change {
+	return Change{
+		Kind:  kind,
+		Key:   t.key,
+		Title: t.entries[0].PublicationTitle,
+		ISSN:  t.issns,
+		Old:   before,
+		New:   after,
+	}
+}
+
+// titleKey returns the key of an entry: title_id, ISSN or the title itself.
+func titleKey(e licensing.Entry) string {
+	if id := strings.TrimSpace(e.TitleID); id != "" {
+		return id
+	}
+	if issns := e.ISSNList(); len(issns) > 0 {
+		return issns[0]
+	}
+	return e.PublicationTitle
+}
+
+// titles groups entries by key, in order of first appearance.
+func (h *Holdings) titles() (result []*title, byKey map[string]*title) {
+	byKey = make(map[string]*title)
+	for _, e := range *h {
+		k := titleKey(e)
+		t, ok := byKey[k]
+		if !ok {
+			t = &title{key: k}
+			byKey[k] = t
+			result = append(result, t)
+		}
+		t.entries = append(t.entries, e)
+		for _, issn := range e.ISSNList() {
+			if !slices.Contains(t.issns, issn) {
+				t.issns = append(t.issns, issn)
+			}
+		}
+	}
+	return result, byKey
+}
+
+// Diff compares an old (before) and a new (after) version of a holdings file.
+// Titles are matched by title_id. Titles without a match, e.g. because a
+// publisher started to use title ids, are matched by any common ISSN. Changes
+// are sorted by kind and key.
+func Diff(before, after Holdings) []Change {
+	var (
+		oldTitles, oldByKey = before.titles()
+		newTitles, newByKey = after.titles()
+		oldByISSN           = make(map[string]*title)
+		matched             = make(map[*title]*title) // new to old
+		changes             []Change
+	)
+	for _, t := range oldTitles {
+		for _, issn := range t.issns {
+			if _, ok := oldByISSN[issn]; !ok {
+				oldByISSN[issn] = t
+			}
+		}
+	}
+	used := make(map[*title]bool)
+	for _, t := range newTitles {
+		if o, ok := oldByKey[t.key]; ok {
+			matched[t] = o
+			used[o] = true
+		}
+	}
+	for _, t := range newTitles {
+		if _, ok := matched[t]; ok {
+			continue
+		}
+		for _, issn := range t.issns {
+			if o, ok := oldByISSN[issn]; ok && !used[o] {
+				if _, taken := newByKey[o.key]; taken {
+					continue
+				}
+				matched[t] = o
+				used[o] = true
+				break
+			}
+		}
+	}
+	for _, t := range newTitles {
+		o, ok := matched[t]
+		if !ok {
+			changes = append(changes, t.change(Added, "", t.coverage()))
+			continue
+		}
+		if a, b := o.coverage(), t.coverage(); a != b {
+			changes = append(changes, t.change(CoverageChanged, a, b))
+		}
+		if a, b := o.embargo(), t.embargo(); a != b {
+			changes = append(changes, t.change(EmbargoChanged, a, b))
+		}
+	}
+	for _, o := range oldTitles {
+		if !used[o] {
+			changes = append(changes, o.change(Removed, o.coverage(), ""))
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Key, b.Key))
+	})
+	return changes
+}
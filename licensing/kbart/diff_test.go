@@ -0,0 +1,38 @@
+package kbart
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	var (
+		old = Holdings{
+			{PublicationTitle: "Same", TitleID: "1", PrintIdentifier: "0000-0000", FirstIssueDate: "2000"},
+			{PublicationTitle: "Gone", TitleID: "2", PrintIdentifier: "1050-124X", FirstIssueDate: "2000"},
+			{PublicationTitle: "Longer", TitleID: "3", PrintIdentifier: "0028-0844", LastIssueDate: "2010"},
+			{PublicationTitle: "Embargo", PrintIdentifier: "2434-561X", Embargo: "P1Y"},
+		}
+		cur = Holdings{
+			{PublicationTitle: "Same", TitleID: "1", PrintIdentifier: "0000-0000", FirstIssueDate: "2000"},
+			{PublicationTitle: "Longer", TitleID: "3", PrintIdentifier: "0028-0844", LastIssueDate: "2020"},
+			// Title id added, matched by ISSN.
+			{PublicationTitle: "Embargo", TitleID: "4", PrintIdentifier: "2434-561X", Embargo: "P2Y"},
+			{PublicationTitle: "New", TitleID: "5", OnlineIdentifier: "0378-5971"},
+		}
+	)
+	var want = []Change{
+		{Kind: Added, Key: "5", Title: "New", ISSN: []string{"0378-5971"}, New: "//-//"},
+		{Kind: CoverageChanged, Key: "3", Title: "Longer", ISSN: []string{"0028-0844"}, Old: "//-2010//", New: "//-2020//"},
+		{Kind: EmbargoChanged, Key: "4", Title: "Embargo", ISSN: []string{"2434-561X"}, Old: "P1Y", New: "P2Y"},
+		{Kind: Removed, Key: "2", Title: "Gone", ISSN: []string{"1050-124X"}, Old: "2000//-//"},
+	}
+	changes := Diff(old, cur)
+	if len(changes) != len(want) {
+		t.Fatalf("got %v, want %v", changes, want)
+	}
+	for i, c := range changes {
+		w := want[i]
+		if c.Kind != w.Kind || c.Key != w.Key || c.Title != w.Title || c.Old != w.Old || c.New != w.New ||
+			len(c.ISSN) != 1 || c.ISSN[0] != w.ISSN[0] {
+			t.Errorf("got %+v, want %+v", c, w)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+package kbart
+
+import (
+	"bufio"
+	"bytes"
+	stdcsv "encoding/csv"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/miku/span/encoding/csv"
+	"github.com/miku/span/encoding/tsv"
+	"github.com/miku/span/licensing"
+)
+
+// Format of a holdings file.
+type Format string
+
+const (
+	FormatTSV  Format = "tsv"
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatODS  Format = "ods"
+)
+
+// sniffSize is the number of bytes inspected to detect format and header.
+const sniffSize = 64 * 1024
+
+// maxHeaderRow is the number of rows searched for a header. Spreadsheets from
+// vendors sometimes start with a title or a generation date.
+const maxHeaderRow = 20
+
+// HeaderAliases maps column names used by vendors to KBART column names.
+// Column names are compared in lowercase, with spaces and dashes replaced by
+// underscores.
+var HeaderAliases = map[string]string{
+	"title":            "publication_title",
+	"titel":            "publication_title",
+	"journal_title":    "publication_title",
+	"journal":          "publication_title",
+	"issn":             "print_identifier",
+	"print_issn":       "print_identifier",
+	"pissn":            "print_identifier",
+	"p_issn":           "print_identifier",
+	"issn_(print)":     "print_identifier",
+	"isbn":             "print_identifier",
+	"print_isbn":       "print_identifier",
+	"eissn":            "online_identifier",
+	"e_issn":           "online_identifier",
+	"online_issn":      "online_identifier",
+	"electronic_issn":  "online_identifier",
+	"issn_(online)":    "online_identifier",
+	"eisbn":            "online_identifier",
+	"e_isbn":           "online_identifier",
+	"online_isbn":      "online_identifier",
+	"start_date":       "date_first_issue_online",
+	"coverage_begin":   "date_first_issue_online",
+	"first_issue_date": "date_first_issue_online",
+	"end_date":         "date_last_issue_online",
+	"coverage_end":     "date_last_issue_online",
+	"last_issue_date":  "date_last_issue_online",
+	"start_volume":     "num_first_vol_online",
+	"first_volume":     "num_first_vol_online",
+	"end_volume":       "num_last_vol_online",
+	"last_volume":      "num_last_vol_online",
+	"start_issue":      "num_first_issue_online",
+	"first_issue":      "num_first_issue_online",
+	"end_issue":        "num_last_issue_online",
+	"last_issue":       "num_last_issue_online",
+	"url":              "title_url",
+	"link":             "title_url",
+	"embargo":          "embargo_info",
+	"publisher":        "publisher_name",
+	"notes":            "coverage_notes",
+	"zdb":              "zdb_id",
+	"zdbid":            "zdb_id",
+}
+
+// columns maps lowercase column names of licensing.Entry to their tag.
+var columns = func() map[string]string {
+	m := make(map[string]string)
+	t := reflect.TypeOf(licensing.Entry{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("csv"); tag != "" && tag != "-" {
+			m[strings.ToLower(tag)] = tag
+		}
+	}
+	return m
+}()
+
+// trimColumn removes whitespace, quotes and byte order mark from a column name.
+func trimColumn(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "\ufeff\"")
+}
+
+// columnName returns the KBART name of a column or the empty string, if the
+// column is not known.
+func columnName(s string) string {
+	s = strings.ToLower(trimColumn(s))
+	if c, ok := columns[s]; ok {
+		return c
+	}
+	s = strings.NewReplacer(" ", "_", "-", "_").Replace(s)
+	if c, ok := columns[s]; ok {
+		return c
+	}
+	return HeaderAliases[s]
+}
+
+// normalizeHeader maps column names to KBART names. Unknown columns are kept
+// as they are. An alias is only used, if the KBART column is not already
+// present, e.g. an "ISBN" column next to a "print_identifier" column is kept.
+func normalizeHeader(header []string) []string {
+	var (
+		result = make([]string, len(header))
+		taken  = make(map[string]bool)
+	)
+	for _, h := range header {
+		if c, ok := columns[strings.ToLower(trimColumn(h))]; ok {
+			taken[c] = true
+		}
+	}
+	for i, h := range header {
+		result[i] = trimColumn(h)
+		c := columnName(h)
+		if c == "" {
+			continue
+		}
+		if !taken[c] || strings.EqualFold(result[i], c) {
+			result[i] = c
+			taken[c] = true
+		}
+	}
+	return result
+}
+
+// isHeader returns true, if a row looks like a header: at least two known
+// columns, one of them a title or identifier.
+func isHeader(row []string) bool {
+	var known int
+	var key bool
+	for _, v := range row {
+		switch c := columnName(v); c {
+		case "":
+			continue
+		case "publication_title", "print_identifier", "online_identifier":
+			key = true
+			fallthrough
+		default:
+			known++
+		}
+	}
+	return known >= 2 && key
+}
+
+// headerRow returns the index of the header row, or zero if no row looks like
+// a header.
+func headerRow(rows [][]string) int {
+	for i, row := range rows {
+		if i == maxHeaderRow {
+			break
+		}
+		if isHeader(row) {
+			return i
+		}
+	}
+	return 0
+}
+
+// Sniff guesses the format of a holdings file from its first bytes. Tab
+// separated content is assumed, if the first line contains tabs or neither
+// commas nor semicolons. The second return value is the field separator.
+func Sniff(p []byte) (Format, rune) {
+	if bytes.HasPrefix(p, []byte("PK\x03\x04")) {
+		switch {
+		case bytes.Contains(p, []byte("application/vnd.oasis.opendocument.spreadsheet")):
+			return FormatODS, 0
+		case bytes.Contains(p, []byte("[Content_Types].xml")), bytes.Contains(p, []byte("xl/")):
+			return FormatXLSX, 0
+		}
+	}
+	p = bytes.TrimPrefix(p, []byte("\ufeff"))
+	line, _, _ := bytes.Cut(bytes.TrimLeft(p, "\r\n"), []byte("\n"))
+	var (
+		tabs       = bytes.Count(line, []byte("\t"))
+		commas     = bytes.Count(line, []byte(","))
+		semicolons = bytes.Count(line, []byte(";"))
+	)
+	switch {
+	case tabs > 0 || commas+semicolons == 0:
+		return FormatTSV, '\t'
+	case semicolons > commas:
+		return FormatCSV, ';'
+	default:
+		return FormatCSV, ','
+	}
+}
+
+// readTSV reads tab separated holdings. Lines before the header are skipped.
+func (h *Holdings) readTSV(br *bufio.Reader, peek []byte) error {
+	var rows [][]string
+	for _, line := range strings.Split(string(peek), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			rows = append(rows, strings.Split(line, "\t"))
+		}
+	}
+	var (
+		skip   = headerRow(rows)
+		header []string
+	)
+	for header == nil {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		header = strings.Split(strings.TrimPrefix(line, "\ufeff"), "\t")
+	}
+	dec := tsv.NewDecoder(br)
+	dec.Header = normalizeHeader(header)
+	for {
+		var entry licensing.Entry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		*h = append(*h, entry)
+	}
+	return nil
+}
+
+// newCSVReader returns a lenient CSV reader.
+func newCSVReader(r io.Reader, comma rune) *stdcsv.Reader {
+	cr := stdcsv.NewReader(r)
+	cr.Comma = comma
+	cr.LazyQuotes = true
+	cr.FieldsPerRecord = -1
+	return cr
+}
+
+// readCSV reads comma or semicolon separated holdings with quoting. Rows
+// before the header are skipped.
+func (h *Holdings) readCSV(r io.Reader, peek []byte, comma rune) error {
+	var (
+		rows [][]string
+		pr   = newCSVReader(bytes.NewReader(peek), comma)
+	)
+	for len(rows) < maxHeaderRow {
+		row, err := pr.Read()
+		if err != nil {
+			// The peeked content may end in the middle of a row.
+			break
+		}
+		rows = append(rows, row)
+	}
+	var (
+		cr     = newCSVReader(r, comma)
+		header []string
+		err    error
+	)
+	for i := 0; i <= headerRow(rows); i++ {
+		if header, err = cr.Read(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	dec := csv.NewDecoder(cr)
+	dec.Header = normalizeHeader(header)
+	for {
+		var entry licensing.Entry
+		err := dec.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		*h = append(*h, entry)
+	}
+	return nil
+}
+
+// readSpreadsheet reads holdings from the first sheet of a XLSX or ODS file.
+// The rows are written to CSV and then decoded, like a CSV file.
+func (h *Holdings) readSpreadsheet(p []byte, format Format) error {
+	var (
+		rows [][]string
+		err  error
+	)
+	switch format {
+	case FormatODS:
+		rows, err = readODS(p)
+	default:
+		rows, err = readXLSX(p)
+	}
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	rows = rows[headerRow(rows):]
+	header := normalizeHeader(rows[0])
+	for _, row := range rows[1:] {
+		for i, v := range row {
+			if i < len(header) && strings.HasPrefix(header[i], "date_") {
+				row[i] = spreadsheetDate(v)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	w := stdcsv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return h.readCSV(&buf, nil, ',')
+}
+
+// rowReader reads the rows of a holdings file in any format understood by
+// Holdings.ReadFrom, starting with the header row, together with their line
+// numbers. Blank rows and rows before the header are skipped.
+type rowReader struct {
+	Format  Format
+	Skipped int // number of rows before the header
+
+	read    func() ([]string, int, error)
+	pending [][]string
+	lines   []int
+}
+
+// newRowReader detects the format of a holdings file and finds its header.
+func newRowReader(r io.Reader) (*rowReader, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	peek, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	var (
+		rr    = &rowReader{}
+		comma rune
+	)
+	switch rr.Format, comma = Sniff(peek); rr.Format {
+	case FormatXLSX, FormatODS:
+		p, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		var rows [][]string
+		if rr.Format == FormatODS {
+			rows, err = readODS(p)
+		} else {
+			rows, err = readXLSX(p)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var i int
+		rr.read = func() ([]string, int, error) {
+			if i == len(rows) {
+				return nil, 0, io.EOF
+			}
+			i++
+			return rows[i-1], i, nil
+		}
+	case FormatCSV:
+		cr := newCSVReader(br, comma)
+		rr.read = func() ([]string, int, error) {
+			row, err := cr.Read()
+			if err != nil {
+				return nil, 0, err
+			}
+			line, _ := cr.FieldPos(0)
+			return row, line, nil
+		}
+	default:
+		var n int
+		rr.read = func() ([]string, int, error) {
+			line, err := br.ReadString('\n')
+			if err == io.EOF && line == "" {
+				return nil, 0, io.EOF
+			}
+			if err != nil && err != io.EOF {
+				return nil, 0, err
+			}
+			n++
+			line = strings.TrimRight(line, "\r\n")
+			if n == 1 {
+				line = strings.TrimPrefix(line, "\ufeff")
+			}
+			return strings.Split(line, "\t"), n, nil
+		}
+	}
+	for len(rr.pending) < maxHeaderRow {
+		row, line, err := rr.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rr.pending = append(rr.pending, row)
+		rr.lines = append(rr.lines, line)
+	}
+	rr.Skipped = headerRow(rr.pending)
+	rr.pending, rr.lines = rr.pending[rr.Skipped:], rr.lines[rr.Skipped:]
+	return rr, nil
+}
+
+// next returns the next row, that is not blank.
+func (rr *rowReader) next() ([]string, int, error) {
+	for {
+		row, line, err := rr.read()
+		if err != nil {
+			return nil, 0, err
+		}
+		if strings.TrimSpace(strings.Join(row, "")) != "" {
+			return row, line, nil
+		}
+	}
+}
+
+// Read returns the next row and its line number, the header first.
+func (rr *rowReader) Read() ([]string, int, error) {
+	if len(rr.pending) > 0 {
+		row, line := rr.pending[0], rr.lines[0]
+		rr.pending, rr.lines = rr.pending[1:], rr.lines[1:]
+		return row, line, nil
+	}
+	return rr.next()
+}
@@ -0,0 +1,105 @@
+package kbart
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// zipFiles returns a zip archive with the given files, in order.
+func zipFiles(t *testing.T, files ...string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadFromFormats(t *testing.T) {
+	var (
+		xlsx = zipFiles(t,
+			"[Content_Types].xml", `<Types/>`,
+			"xl/workbook.xml", `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Titles" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+			"xl/_rels/workbook.xml.rels", `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+			"xl/sharedStrings.xml", `<sst><si><t>Title</t></si><si><t>ISSN</t></si><si><t>Start Date</t></si>
+<si><r><t>Journal </t></r><r><t>of Examples</t></r></si><si><t>1050-124X</t></si></sst>`,
+			"xl/worksheets/sheet1.xml", `<worksheet><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>Generated 2024-01-01</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>0</v></c><c r="B2" t="s"><v>1</v></c><c r="D2" t="s"><v>2</v></c></row>
+<row r="3"><c r="A3" t="s"><v>3</v></c><c r="B3" t="s"><v>4</v></c><c r="D3"><v>36526</v></c></row>
+</sheetData></worksheet>`)
+		ods = zipFiles(t,
+			"mimetype", "application/vnd.oasis.opendocument.spreadsheet",
+			"content.xml", `<office:document-content
+ xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+ xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+ xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:spreadsheet>
+<table:table table:name="Titles">
+<table:table-row><table:table-cell><text:p>publication_title</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="2"/><table:table-cell><text:p>eISSN</text:p></table:table-cell>
+<table:table-cell><text:p>date_first_issue_online</text:p></table:table-cell><table:table-cell table:number-columns-repeated="1000"/></table:table-row>
+<table:table-row><table:table-cell><text:p>Journal of Examples</text:p></table:table-cell>
+<table:table-cell table:number-columns-repeated="2"/><table:table-cell><text:p>1050-124X</text:p></table:table-cell>
+<table:table-cell office:value-type="date" office:date-value="2000-01-01T00:00:00"><text:p>01.01.2000</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="1048000"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`)
+	)
+	var tests = []struct {
+		name   string
+		p      []byte
+		format Format
+	}{
+		{"tsv", []byte("publication_title\tprint_identifier\tdate_first_issue_online\nJournal of Examples\t1050-124X\t2000-01-01\n"), FormatTSV},
+		{"tsv-preamble", []byte("\ufeffTitle list\n\nTitle\tISSN\tStart Date\nJournal of Examples\t1050-124X\t2000-01-01"), FormatTSV},
+		{"csv", []byte("\"Journal Title\",\"Print ISSN\",Notes,Start Date\n\"Journal of Examples\",1050-124X,\"a, b\",2000-01-01\n"), FormatCSV},
+		{"csv-semicolon", []byte("publication_title;print_identifier;date_first_issue_online\r\nJournal of Examples;1050-124X;2000-01-01\r\n"), FormatCSV},
+		{"xlsx", xlsx, FormatXLSX},
+		{"ods", ods, FormatODS},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if format, _ := Sniff(test.p); format != test.format {
+				t.Fatalf("Sniff: got %s, want %s", format, test.format)
+			}
+			var h Holdings
+			if _, err := h.ReadFrom(bytes.NewReader(test.p)); err != nil {
+				t.Fatal(err)
+			}
+			if len(h) != 1 {
+				t.Fatalf("got %d entries, want 1: %v", len(h), h)
+			}
+			e := h[0]
+			if e.PublicationTitle != "Journal of Examples" {
+				t.Errorf("got title %q", e.PublicationTitle)
+			}
+			if issns := e.ISSNList(); len(issns) != 1 || issns[0] != "1050-124X" {
+				t.Errorf("got ISSN %v", issns)
+			}
+			if e.FirstIssueDate != "2000-01-01" {
+				t.Errorf("got first issue date %q", e.FirstIssueDate)
+			}
+		})
+	}
+}
+
+func TestNormalizeHeader(t *testing.T) {
+	var (
+		header = []string{"Title", "ISSN", "print_identifier", "eISSN", "Notes", "custom"}
+		want   = []string{"publication_title", "ISSN", "print_identifier", "online_identifier", "coverage_notes", "custom"}
+	)
+	if got := normalizeHeader(header); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
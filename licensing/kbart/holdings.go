@@ -12,12 +12,12 @@
 package kbart
 
 import (
+	"bufio"
 	"io"
 	"maps"
 	"regexp"
 	"slices"
 
-	"github.com/miku/span/encoding/tsv"
 	"github.com/miku/span/licensing"
 	"github.com/miku/span/xio"
 )
@@ -27,28 +27,41 @@ import (
 // methods.
 type Holdings []licensing.Entry
 
-// ReadFrom create holdings struct from a reader. Expects tab separated content with
-// a single header row. TODO: This is not exactly what ReadFrom is for.
+// ReadFrom create holdings struct from a reader. The format is detected from
+// the content: tab or comma separated values with a header row, or the first
+// sheet of a XLSX or ODS file. Rows before the header and vendor specific
+// column names are handled, refs. HeaderAliases. TODO: This is not exactly
+// what ReadFrom is for.
 func (h *Holdings) ReadFrom(r io.Reader) (int64, error) {
-	var wc xio.WriteCounter
-	dec := tsv.NewDecoder(io.TeeReader(r, &wc))
-	for {
-		var entry licensing.Entry
-		err := dec.Decode(&entry)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return 0, err
+	var (
+		wc xio.WriteCounter
+		br = bufio.NewReaderSize(io.TeeReader(r, &wc), sniffSize)
+	)
+	peek, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	switch format, comma := Sniff(peek); format {
+	case FormatXLSX, FormatODS:
+		var p []byte
+		if p, err = io.ReadAll(br); err == nil {
+			err = h.readSpreadsheet(p, format)
 		}
-		*h = append(*h, entry)
+	case FormatCSV:
+		err = h.readCSV(br, peek, comma)
+	default:
+		err = h.readTSV(br, peek)
+	}
+	if err != nil {
+		return 0, err
 	}
 	return int64(wc.Count()), nil
 }
 
 // SerialNumberMap creates a map from ISSN to associated licensing entries.
 // This is here for performance mostly, so we can access relevant licensing
-// entry by ISSN. TODO: Do not replicate entries, just index into them.
+// entry by ISSN. Entries are replicated per ISSN, see SerialNumberIndex for a
+// more compact alternative.
 func (h *Holdings) SerialNumberMap() map[string][]licensing.Entry {
 	cache := make(map[string]map[licensing.Entry]struct{})
 	for _, e := range *h {
@@ -84,22 +97,32 @@ func (h *Holdings) TitleMap() map[string][]licensing.Entry {
 	return result
 }
 
+// wisoPatterns extract WISO database names from title URLs.
+var wisoPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`https://www.wiso-net.de/toc_list/([A-Z]{3,4})`),
+	regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=:2:2:([A-Z]{3,4})`),
+	regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=([A-Z]{3,4})`),
+}
+
+// wisoDatabases returns the WISO database names found in the title URL of an
+// entry.
+func wisoDatabases(e licensing.Entry) (names []string) {
+	for _, p := range wisoPatterns {
+		matches := p.FindStringSubmatch(e.TitleURL)
+		if len(matches) < 2 {
+			continue
+		}
+		names = append(names, matches[1])
+	}
+	return names
+}
+
 // WisoDatabaseMap derives a structure from the holdings file, that maps WISO
 // database names to the associated entries, refs. #9534.
 func (h *Holdings) WisoDatabaseMap() map[string][]licensing.Entry {
-	patterns := []*regexp.Regexp{
-		regexp.MustCompile(`https://www.wiso-net.de/toc_list/([A-Z]{3,4})`),
-		regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=:2:2:([A-Z]{3,4})`),
-		regexp.MustCompile(`https://www.wiso-net.de/.*dbShortcut=([A-Z]{3,4})`),
-	}
 	cache := make(map[string]map[licensing.Entry]bool)
 	for _, e := range *h {
-		for _, p := range patterns {
-			matches := p.FindStringSubmatch(e.TitleURL)
-			if len(matches) < 2 {
-				continue
-			}
-			db := matches[1]
+		for _, db := range wisoDatabases(e) {
 			if cache[db] == nil {
 				cache[db] = make(map[licensing.Entry]bool)
 			}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
 
@@ -134,3 +135,41 @@ func TestWisoDatabaseMap(t *testing.T) {
 		t.Errorf("WisoDatabaseMap: got %v, want %v", len(m), want)
 	}
 }
+
+func TestIndex(t *testing.T) {
+	var (
+		a = licensing.Entry{PublicationTitle: "A", PrintIdentifier: "1111-1111", OnlineIdentifier: "2222-2222"}
+		b = licensing.Entry{PublicationTitle: "B", PrintIdentifier: "1111-1111",
+			TitleURL: "https://www.wiso-net.de/toc_list/ABC"}
+		c = licensing.Entry{PublicationTitle: "C", PrintIdentifier: "3-658-10838-X",
+			TitleID: "10.1007/978-3-658-10838-0", CoverageDepth: "ebook"}
+		d = licensing.Entry{PublicationTitle: "D", PrintIdentifier: "9783662479841"}
+		h = Holdings{a, b, a, c, d}
+	)
+	h.Unique()
+	if len(h) != 4 {
+		t.Fatalf("Unique: got %d entries, want 4", len(h))
+	}
+	h.Intern()
+	if h[0] != a || h[1] != b || h[2] != c {
+		t.Errorf("Intern: entries changed: %v", h)
+	}
+	var tests = []struct {
+		index Index
+		key   string
+		want  []int32
+	}{
+		{h.SerialNumberIndex(), "1111-1111", []int32{0, 1}},
+		{h.SerialNumberIndex(), "2222-2222", []int32{0}},
+		{h.TitleIndex(), "b", []int32{1}},
+		{h.WisoDatabaseIndex(), "ABC", []int32{1}},
+		{h.ISBNIndex(), "9783658108380", []int32{2}},
+		{h.ISBNIndex(), "9783662479841", nil}, // not an ebook entry
+		{h.DOIPrefixIndex(), "10.1007/978-3-658-10838-0", []int32{2}},
+	}
+	for _, test := range tests {
+		if got := test.index[test.key]; !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.key, got, test.want)
+		}
+	}
+}
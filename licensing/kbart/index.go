@@ -0,0 +1,118 @@
+package kbart
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/miku/span/licensing"
+)
+
+// Index maps a key, e.g. an ISSN, to offsets of entries in a holdings file.
+// Unlike the maps returned by SerialNumberMap and friends, an index does not
+// copy entries, which keeps memory usage low for large holdings files.
+type Index map[string][]int32
+
+// index builds an index from the keys of each entry.
+func (h *Holdings) index(keys func(licensing.Entry) []string) Index {
+	idx := make(Index)
+	for i, e := range *h {
+		for _, k := range keys(e) {
+			offsets := idx[k]
+			if n := len(offsets); n > 0 && offsets[n-1] == int32(i) {
+				continue
+			}
+			idx[k] = append(offsets, int32(i))
+		}
+	}
+	return idx
+}
+
+// Unique removes duplicate entries, keeping the first occurrence.
+func (h *Holdings) Unique() {
+	seen := make(map[licensing.Entry]struct{}, len(*h))
+	*h = slices.DeleteFunc(*h, func(e licensing.Entry) bool {
+		if _, ok := seen[e]; ok {
+			return true
+		}
+		seen[e] = struct{}{}
+		return false
+	})
+}
+
+// SerialNumberIndex maps ISSN to entry offsets.
+func (h *Holdings) SerialNumberIndex() Index {
+	return h.index(func(e licensing.Entry) []string { return e.ISSNList() })
+}
+
+// ZDBIndex maps normalized ZDB-ID to entry offsets.
+func (h *Holdings) ZDBIndex() Index {
+	return h.index(func(e licensing.Entry) []string {
+		if zdb := e.ZDB(); zdb != "" {
+			return []string{zdb}
+		}
+		return nil
+	})
+}
+
+// TitleIndex maps a normalized title to entry offsets, refs.
+// licensing.NormalizeTitle.
+func (h *Holdings) TitleIndex() Index {
+	return h.index(func(e licensing.Entry) []string {
+		if t := licensing.NormalizeTitle(e.PublicationTitle); t != "" {
+			return []string{t}
+		}
+		return nil
+	})
+}
+
+// ISBNIndex maps ISBN-13 of ebook entries to entry offsets.
+func (h *Holdings) ISBNIndex() Index {
+	return h.index(func(e licensing.Entry) []string {
+		if !e.IsEbook() {
+			return nil
+		}
+		return e.ISBNList()
+	})
+}
+
+// DOIPrefixIndex maps the DOI of ebook entries to entry offsets. Chapters
+// usually have a DOI, that starts with the DOI of the book, so the keys can be
+// used as prefixes.
+func (h *Holdings) DOIPrefixIndex() Index {
+	return h.index(func(e licensing.Entry) []string {
+		if doi := e.DOI(); doi != "" && e.IsEbook() {
+			return []string{doi}
+		}
+		return nil
+	})
+}
+
+// WisoDatabaseIndex maps WISO database names to entry offsets, refs. #9534.
+func (h *Holdings) WisoDatabaseIndex() Index {
+	return h.index(wisoDatabases)
+}
+
+// Intern replaces repeated field values, like publisher names, dates or
+// embargo strings, with a single shared copy. All values are copied, so the
+// entries do not keep the lines they were parsed from alive.
+func (h *Holdings) Intern() {
+	pool := make(map[string]string)
+	for i := range *h {
+		v := reflect.ValueOf(&(*h)[i]).Elem()
+		for j := 0; j < v.NumField(); j++ {
+			f := v.Field(j)
+			if f.Kind() != reflect.String || !f.CanSet() {
+				continue
+			}
+			s := f.String()
+			if c, ok := pool[s]; ok {
+				f.SetString(c)
+				continue
+			}
+			c := strings.Clone(s)
+			pool[c] = c
+			f.SetString(c)
+		}
+	}
+}
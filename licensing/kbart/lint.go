@@ -0,0 +1,329 @@
+package kbart
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miku/span/licensing"
+)
+
+// PhaseIIColumns are the columns of a KBART Phase II file, in order, refs.
+// NISO RP-9-2014, section 6.6.
+var PhaseIIColumns = []string{
+	"publication_title",
+	"print_identifier",
+	"online_identifier",
+	"date_first_issue_online",
+	"num_first_vol_online",
+	"num_first_issue_online",
+	"date_last_issue_online",
+	"num_last_vol_online",
+	"num_last_issue_online",
+	"title_url",
+	"first_author",
+	"title_id",
+	"embargo_info",
+	"coverage_depth",
+	"notes",
+	"publisher_name",
+	"publication_type",
+	"date_monograph_published_print",
+	"date_monograph_published_online",
+	"monograph_volume",
+	"monograph_edition",
+	"first_editor",
+	"parent_publication_title_id",
+	"preceding_publication_title_id",
+	"access_type",
+}
+
+var (
+	issnLike = regexp.MustCompile(`^[0-9]{4}-?[0-9]{3}[0-9xX]$`)
+	isbnLike = regexp.MustCompile(`^(97[89])?[0-9]{9}[0-9xX]$`)
+)
+
+// Severity of a problem.
+type Severity string
+
+const (
+	// SeverityError marks rows, that will not be interpreted correctly.
+	SeverityError Severity = "error"
+	// SeverityWarning marks rows, that are probably incomplete.
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a single finding in a KBART file. Line numbers start at one, with
+// the header on the first line.
+type Problem struct {
+	Line     int      `json:"line"`
+	Column   string   `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats a problem for display.
+func (p Problem) String() string {
+	return fmt.Sprintf("%d\t%s\t%s\t%s", p.Line, p.Severity, p.Column, p.Message)
+}
+
+// Linter checks a KBART file row by row and can write a normalized version of
+// it, with ISSN in 1234-567X form, dates as YYYY, YYYY-MM or YYYY-MM-DD and
+// surrounding whitespace removed. All columns, including non-standard ones,
+// are kept.
+type Linter struct {
+	Output      io.Writer // If not nil, write normalized file here.
+	DropInvalid bool      // Do not write rows with errors to output.
+
+	problems []Problem
+	line     int
+	header   []string
+	index    map[string]int // normalized column name to position
+}
+
+// report adds a problem for the current line.
+func (l *Linter) report(column string, severity Severity, format string, args ...any) {
+	l.problems = append(l.problems, Problem{
+		Line:     l.line,
+		Column:   column,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// countErrors returns the number of errors reported since a given problem offset.
+func (l *Linter) countErrors(since int) (n int) {
+	for _, p := range l.problems[since:] {
+		if p.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// checkHeader checks the column names. Vendor column names are mapped like
+// when the file is loaded, refs. HeaderAliases.
+func (l *Linter) checkHeader(header []string) {
+	l.header = normalizeHeader(header)
+	l.index = make(map[string]int)
+	for i, name := range l.header {
+		if _, ok := l.index[name]; ok {
+			l.report(name, SeverityError, "duplicate column")
+			continue
+		}
+		l.index[name] = i
+	}
+	_, hasPrint := l.index["print_identifier"]
+	_, hasOnline := l.index["online_identifier"]
+	if !hasPrint && !hasOnline {
+		l.report("", SeverityError, "neither print_identifier nor online_identifier column")
+	}
+	for _, name := range PhaseIIColumns {
+		// Some Phase II columns, like notes, are loaded under another name.
+		key := columnName(name)
+		if key == "" {
+			key = name
+		}
+		if _, ok := l.index[key]; !ok {
+			l.report(name, SeverityWarning, "missing KBART Phase II column")
+		}
+	}
+}
+
+// get returns the value of a column in a row or the empty string.
+func (l *Linter) get(row []string, column string) string {
+	i, ok := l.index[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// set updates the value of a column in a row, if the column exists.
+func (l *Linter) set(row []string, column, value string) {
+	if i, ok := l.index[column]; ok && i < len(row) {
+		row[i] = value
+	}
+}
+
+// validSerialNumber returns true, if the check digit of a normalized ISSN is
+// correct.
+func validSerialNumber(issn string) bool {
+	digits := strings.Replace(issn, "-", "", 1)
+	if len(digits) != 8 {
+		return false
+	}
+	var sum int
+	for i := 0; i < 7; i++ {
+		sum += int(digits[i]-'0') * (8 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return digits[7] == 'X'
+	}
+	return int(digits[7]-'0') == check
+}
+
+// checkIdentifier checks and normalizes a print or online identifier, which
+// may be an ISSN or an ISBN.
+func (l *Linter) checkIdentifier(row []string, column string) {
+	v := l.get(row, column)
+	if v == "" {
+		return
+	}
+	switch {
+	case issnLike.MatchString(v):
+		issn := licensing.NormalizeSerialNumber(strings.Replace(v, "-", "", 1))
+		if !validSerialNumber(issn) {
+			l.report(column, SeverityError, "invalid ISSN check digit: %s", v)
+		}
+		l.set(row, column, issn)
+	case isbnLike.MatchString(strings.ReplaceAll(v, "-", "")):
+	default:
+		l.report(column, SeverityError, "neither ISSN nor ISBN: %s", v)
+	}
+}
+
+// checkDate checks and normalizes a date column.
+func (l *Linter) checkDate(row []string, column string) (time.Time, bool) {
+	v := l.get(row, column)
+	if v == "" {
+		return time.Time{}, false
+	}
+	t, g, err := licensing.ParseDate(v)
+	if err != nil {
+		l.report(column, SeverityError, "cannot parse date: %s", v)
+		return time.Time{}, false
+	}
+	switch g {
+	case licensing.GranularityYear:
+		l.set(row, column, t.Format("2006"))
+	case licensing.GranularityMonth:
+		l.set(row, column, t.Format("2006-01"))
+	default:
+		l.set(row, column, t.Format("2006-01-02"))
+	}
+	return t, true
+}
+
+// checkRange reports a range, where the first value is greater than the last.
+func (l *Linter) checkRange(row []string, first, last string) {
+	a, errA := strconv.Atoi(l.get(row, first))
+	b, errB := strconv.Atoi(l.get(row, last))
+	if errA == nil && errB == nil && a > b {
+		l.report(first, SeverityError, "%s %d after %s %d", first, a, last, b)
+	}
+}
+
+// checkRow checks and normalizes a single row in place.
+func (l *Linter) checkRow(row []string) {
+	switch {
+	case len(row) > len(l.header):
+		l.report("", SeverityError, "got %d columns, header has %d", len(row), len(l.header))
+	case len(row) < len(l.header):
+		l.report("", SeverityWarning, "got %d columns, header has %d", len(row), len(l.header))
+	}
+	for i := range row {
+		row[i] = strings.TrimSpace(row[i])
+	}
+	if l.get(row, "publication_title") == "" {
+		l.report("publication_title", SeverityWarning, "empty title")
+	}
+	l.checkIdentifier(row, "print_identifier")
+	l.checkIdentifier(row, "online_identifier")
+	if l.get(row, "print_identifier") == "" && l.get(row, "online_identifier") == "" &&
+		len(licensing.FindSerialNumbers(l.get(row, "all_issns"))) == 0 {
+		l.report("", SeverityWarning, "no identifier, entry cannot be matched by ISSN")
+	}
+	first, okFirst := l.checkDate(row, "date_first_issue_online")
+	last, okLast := l.checkDate(row, "date_last_issue_online")
+	if okFirst && okLast && first.After(last) {
+		l.report("date_first_issue_online", SeverityError, "first issue date %s after last issue date %s",
+			l.get(row, "date_first_issue_online"), l.get(row, "date_last_issue_online"))
+	}
+	l.checkDate(row, "date_monograph_published_print")
+	l.checkDate(row, "date_monograph_published_online")
+	l.checkRange(row, "num_first_vol_online", "num_last_vol_online")
+	if v := l.get(row, "embargo_info"); v != "" {
+		v = strings.ToUpper(v)
+		if _, _, err := licensing.Embargo(v).MovingWalls(time.Now()); err != nil {
+			l.report("embargo_info", SeverityError, "invalid embargo: %s", v)
+		}
+		l.set(row, "embargo_info", v)
+	}
+	if v := l.get(row, "title_url"); v != "" && !strings.HasPrefix(v, "http://") && !strings.HasPrefix(v, "https://") {
+		l.report("title_url", SeverityWarning, "not a http(s) link: %s", v)
+	}
+}
+
+// Run checks a KBART file and returns the problems found, in the order of
+// the lines. Files are read like Holdings.ReadFrom does, so TSV, CSV, XLSX
+// and ODS files with vendor column names and rows before the header are
+// accepted. If Output is set, the normalized file is written to it, tab
+// separated.
+func (l *Linter) Run(r io.Reader) ([]Problem, error) {
+	var bw *bufio.Writer
+	l.problems, l.line, l.header = nil, 0, nil
+	if l.Output != nil {
+		bw = bufio.NewWriter(l.Output)
+	}
+	rr, err := newRowReader(r)
+	if err != nil {
+		return nil, err
+	}
+	spreadsheet := rr.Format == FormatXLSX || rr.Format == FormatODS
+	for {
+		row, line, err := rr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return l.problems, err
+		}
+		l.line = line
+		if l.header == nil {
+			if rr.Skipped > 0 {
+				l.report("", SeverityWarning, "skipped %d rows before header", rr.Skipped)
+			}
+			l.checkHeader(row)
+			if bw != nil {
+				if _, err := bw.WriteString(strings.Join(l.header, "\t") + "\n"); err != nil {
+					return l.problems, err
+				}
+			}
+			continue
+		}
+		if spreadsheet {
+			// Trailing empty cells are not stored.
+			for len(row) < len(l.header) {
+				row = append(row, "")
+			}
+			for i, v := range row {
+				if i < len(l.header) && strings.HasPrefix(l.header[i], "date_") {
+					row[i] = spreadsheetDate(v)
+				}
+			}
+		}
+		since := len(l.problems)
+		l.checkRow(row)
+		if bw == nil || (l.DropInvalid && l.countErrors(since) > 0) {
+			continue
+		}
+		if _, err := bw.WriteString(strings.Join(row, "\t") + "\n"); err != nil {
+			return l.problems, err
+		}
+	}
+	if l.header == nil {
+		l.report("", SeverityError, "empty file")
+	}
+	if bw != nil {
+		if err := bw.Flush(); err != nil {
+			return l.problems, err
+		}
+	}
+	return l.problems, nil
+}
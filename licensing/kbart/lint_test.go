@@ -0,0 +1,112 @@
+package kbart
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLinter(t *testing.T) {
+	var (
+		header = strings.Join(PhaseIIColumns, "\t")
+		row    = func(values map[string]string) string {
+			var cols []string
+			for _, name := range PhaseIIColumns {
+				cols = append(cols, values[name])
+			}
+			return strings.Join(cols, "\t")
+		}
+		input = strings.Join([]string{
+			header,
+			row(map[string]string{"publication_title": "Ok", "print_identifier": "1050124x",
+				"date_first_issue_online": "2001-1", "embargo_info": " p1y "}),
+			row(map[string]string{"publication_title": "Bad ISSN", "print_identifier": "0028-0841"}),
+			row(map[string]string{"publication_title": "Dates", "online_identifier": "0028-0844",
+				"date_first_issue_online": "2010", "date_last_issue_online": "2001"}),
+			row(map[string]string{"publication_title": "Embargo", "online_identifier": "0028-0844",
+				"embargo_info": "X1Y"}),
+			row(map[string]string{"publication_title": "Volumes", "online_identifier": "0028-0844",
+				"num_first_vol_online": "10", "num_last_vol_online": "2"}),
+			row(map[string]string{"publication_title": "Book", "online_identifier": "978-3-16-148410-0",
+				"date_first_issue_online": "yesterday"}),
+		}, "\n") + "\n"
+		buf bytes.Buffer
+		l   = Linter{Output: &buf, DropInvalid: true}
+	)
+	problems, err := l.Run(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want = []Problem{
+		{3, "print_identifier", SeverityError, "invalid ISSN check digit: 0028-0841"},
+		{4, "date_first_issue_online", SeverityError, "first issue date 2010 after last issue date 2001"},
+		{5, "embargo_info", SeverityError, "invalid embargo: X1Y"},
+		{6, "num_first_vol_online", SeverityError, "num_first_vol_online 10 after num_last_vol_online 2"},
+		{7, "date_first_issue_online", SeverityError, "cannot parse date: yesterday"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("got %v, want %v", problems, want)
+	}
+	for i := range want {
+		if problems[i] != want[i] {
+			t.Errorf("got %v, want %v", problems[i], want[i])
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header and one row", len(lines))
+	}
+	wantRow := row(map[string]string{"publication_title": "Ok", "print_identifier": "1050-124X",
+		"date_first_issue_online": "2001-01", "embargo_info": "P1Y"})
+	if lines[1] != wantRow {
+		t.Errorf("got %q, want %q", lines[1], wantRow)
+	}
+}
+
+func TestLinterHeader(t *testing.T) {
+	var l Linter
+	problems, err := l.Run(strings.NewReader("publication_title\tzdb_id\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != len(PhaseIIColumns) {
+		t.Fatalf("got %d problems, want %d", len(problems), len(PhaseIIColumns))
+	}
+	if p := problems[0]; p.Line != 1 || p.Severity != SeverityError {
+		t.Errorf("got %v, want missing identifier error", p)
+	}
+}
+
+func TestLinterCSV(t *testing.T) {
+	var (
+		input = "Export;2024-01-01\n" +
+			"Title;ISSN;eISSN;Start Date;End Date;Embargo;notes\n" +
+			"\"Journal; of Tests\";0028-0836;;2001;1999;P1Y;\n"
+		buf bytes.Buffer
+		l   = Linter{Output: &buf}
+	)
+	problems, err := l.Run(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []Problem
+	for _, p := range problems {
+		if p.Column == "notes" || p.Column == "publication_title" {
+			t.Errorf("got %v, want column recognized", p)
+		}
+		if p.Severity == SeverityError {
+			errs = append(errs, p)
+		}
+	}
+	want := Problem{3, "date_first_issue_online", SeverityError, "first issue date 2001 after last issue date 1999"}
+	if len(errs) != 1 || errs[0] != want {
+		t.Errorf("got %v, want %v", errs, want)
+	}
+	if problems[0].Message != "skipped 1 rows before header" {
+		t.Errorf("got %v, want skipped rows warning", problems[0])
+	}
+	header, _, _ := strings.Cut(buf.String(), "\n")
+	if !strings.HasPrefix(header, "publication_title\tprint_identifier\tonline_identifier\t") {
+		t.Errorf("got header %q", header)
+	}
+}
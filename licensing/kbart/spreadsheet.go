@@ -0,0 +1,275 @@
+package kbart
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openZipFile returns the content of a file in a zip archive.
+func openZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("missing %s", name)
+}
+
+// columnIndex returns the zero based column of a cell reference like "AB12".
+func columnIndex(ref string) int {
+	var col int
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+	}
+	return col - 1
+}
+
+// setCell sets a value in a row, growing the row as needed.
+func setCell(row []string, i int, v string) []string {
+	for len(row) <= i {
+		row = append(row, "")
+	}
+	row[i] = v
+	return row
+}
+
+// xlsxFirstSheet returns the path of the first worksheet of a workbook.
+func xlsxFirstSheet(zr *zip.Reader) string {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	const fallback = "xl/worksheets/sheet1.xml"
+	p, err := openZipFile(zr, "xl/workbook.xml")
+	if err != nil || xml.Unmarshal(p, &workbook) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	if p, err = openZipFile(zr, "xl/_rels/workbook.xml.rels"); err != nil || xml.Unmarshal(p, &rels) != nil {
+		return fallback
+	}
+	for _, r := range rels.Relationships {
+		if r.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(r.Target, "/")
+		}
+		return path.Join("xl", r.Target)
+	}
+	return fallback
+}
+
+// xlsxSharedStrings returns the shared strings table of a workbook.
+func xlsxSharedStrings(zr *zip.Reader) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	p, err := openZipFile(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, nil // Workbooks without text have no shared strings.
+	}
+	if err := xml.Unmarshal(p, &sst); err != nil {
+		return nil, err
+	}
+	result := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		if len(item.Runs) == 0 {
+			result[i] = item.Text
+			continue
+		}
+		var sb strings.Builder
+		for _, r := range item.Runs {
+			sb.WriteString(r.Text)
+		}
+		result[i] = sb.String()
+	}
+	return result, nil
+}
+
+// readXLSX returns the rows of the first sheet of an Office Open XML
+// workbook. Dates are returned as stored, usually as a serial number.
+func readXLSX(p []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(p), int64(len(p)))
+	if err != nil {
+		return nil, err
+	}
+	shared, err := xlsxSharedStrings(zr)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := openZipFile(zr, xlsxFirstSheet(zr))
+	if err != nil {
+		return nil, err
+	}
+	type cell struct {
+		Ref    string `xml:"r,attr"`
+		Type   string `xml:"t,attr"`
+		Value  string `xml:"v"`
+		Inline string `xml:"is>t"`
+	}
+	var (
+		dec  = xml.NewDecoder(bytes.NewReader(sheet))
+		rows [][]string
+	)
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok || se.Name.Local != "row" {
+			continue
+		}
+		var r struct {
+			Cells []cell `xml:"c"`
+		}
+		if err := dec.DecodeElement(&r, &se); err != nil {
+			return nil, err
+		}
+		var row []string
+		for i, c := range r.Cells {
+			v := c.Value
+			switch c.Type {
+			case "s":
+				k, err := strconv.Atoi(v)
+				if err != nil || k < 0 || k >= len(shared) {
+					return nil, fmt.Errorf("invalid shared string reference: %s", v)
+				}
+				v = shared[k]
+			case "inlineStr":
+				v = c.Inline
+			}
+			col := i
+			if c.Ref != "" {
+				col = columnIndex(c.Ref)
+			}
+			row = setCell(row, col, v)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readODS returns the rows of the first table of an OpenDocument spreadsheet.
+// Dates are returned as YYYY-MM-DD.
+func readODS(p []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(p), int64(len(p)))
+	if err != nil {
+		return nil, err
+	}
+	content, err := openZipFile(zr, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	type cell struct {
+		XMLName   xml.Name
+		Repeated  int      `xml:"number-columns-repeated,attr"`
+		ValueType string   `xml:"value-type,attr"`
+		Date      string   `xml:"date-value,attr"`
+		Text      []string `xml:"p"`
+	}
+	var (
+		dec    = xml.NewDecoder(bytes.NewReader(content))
+		rows   [][]string
+		tables int
+	)
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "table":
+			if tables++; tables > 1 {
+				return rows, nil
+			}
+			continue
+		case "table-row":
+		default:
+			continue
+		}
+		var r struct {
+			Cells []cell `xml:",any"`
+		}
+		if err := dec.DecodeElement(&r, &se); err != nil {
+			return nil, err
+		}
+		var row []string
+		for _, c := range r.Cells {
+			if c.XMLName.Local != "table-cell" && c.XMLName.Local != "covered-table-cell" {
+				continue
+			}
+			v := strings.Join(c.Text, "\n")
+			if c.ValueType == "date" && len(c.Date) >= 10 {
+				v = c.Date[:10]
+			}
+			// Repeated empty cells often fill a row up to the last column.
+			n := max(1, c.Repeated)
+			if v == "" {
+				row = append(row, make([]string, n)...)
+				continue
+			}
+			for ; n > 0; n-- {
+				row = append(row, v)
+			}
+		}
+		// Trailing empty cells and empty rows carry no information.
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// spreadsheetDate converts a spreadsheet serial date number, e.g. 36526, to
+// YYYY-MM-DD. Small numbers are taken to be years and other values are
+// returned unchanged.
+func spreadsheetDate(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 3000 || f > 2958465 {
+		return v
+	}
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	return epoch.AddDate(0, 0, int(f)).Format("2006-01-02")
+}
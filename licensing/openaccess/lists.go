@@ -0,0 +1,126 @@
+package openaccess
+
+import (
+	"bufio"
+	"hash/fnv"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/segmentio/encoding/json"
+
+	"github.com/miku/span/formats/finc"
+	"github.com/miku/span/licensing"
+)
+
+// DOAJ marks records in journals listed in the Directory of Open Access
+// Journals as open access.
+type DOAJ map[string]struct{}
+
+// ReadDOAJ reads ISSN from the DOAJ journal CSV export or from a list with
+// one ISSN per line. Any value in 1234-567X form counts.
+func ReadDOAJ(r io.Reader) (DOAJ, error) {
+	var (
+		d  = make(DOAJ)
+		br = bufio.NewReader(r)
+	)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		for _, issn := range licensing.FindSerialNumbers(line) {
+			d[strings.ToUpper(issn)] = struct{}{}
+		}
+	}
+	return d, nil
+}
+
+// Resolve returns open, if any ISSN of the record is listed.
+func (d DOAJ) Resolve(is finc.IntermediateSchema) (Status, string) {
+	for _, issn := range is.ISSNList() {
+		if _, ok := d[strings.ToUpper(issn)]; ok {
+			return Open, "doaj:" + issn
+		}
+	}
+	return Unknown, ""
+}
+
+// DOIList marks records with a listed DOI as open access, e.g. from an
+// Unpaywall snapshot. To keep memory bounded for hundreds of millions of
+// DOI, only a sorted list of 64-bit hashes is kept, which takes eight bytes
+// per DOI.
+type DOIList struct {
+	Name   string // Used as evidence, e.g. "unpaywall".
+	hashes []uint64
+}
+
+// doiHash returns the hash of a normalized DOI.
+func doiHash(doi string) uint64 {
+	doi = strings.ToLower(strings.TrimSpace(doi))
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		doi = strings.TrimPrefix(doi, prefix)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(doi))
+	return h.Sum64()
+}
+
+// ReadDOIList reads DOI of open access works. Each line is either a DOI or a
+// JSON object with "doi" and "is_oa" fields, like in an Unpaywall snapshot.
+// Objects with "is_oa" false are skipped.
+func ReadDOIList(r io.Reader) (*DOIList, error) {
+	var (
+		l  = &DOIList{Name: "doi"}
+		br = bufio.NewReader(r)
+	)
+	for {
+		line, err := br.ReadString('\n')
+		if err == io.EOF && line == "" {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "{"):
+			var doc struct {
+				DOI  string `json:"doi"`
+				IsOA bool   `json:"is_oa"`
+			}
+			if err := json.Unmarshal([]byte(line), &doc); err != nil {
+				return nil, err
+			}
+			if doc.IsOA && doc.DOI != "" {
+				l.hashes = append(l.hashes, doiHash(doc.DOI))
+			}
+		default:
+			l.hashes = append(l.hashes, doiHash(line))
+		}
+	}
+	slices.Sort(l.hashes)
+	l.hashes = slices.Clip(slices.Compact(l.hashes))
+	return l, nil
+}
+
+// Len returns the number of distinct DOI in the list.
+func (l *DOIList) Len() int {
+	return len(l.hashes)
+}
+
+// Resolve returns open, if the DOI of the record is listed.
+func (l *DOIList) Resolve(is finc.IntermediateSchema) (Status, string) {
+	if is.DOI == "" {
+		return Unknown, ""
+	}
+	if _, ok := slices.BinarySearch(l.hashes, doiHash(is.DOI)); ok {
+		return Open, l.Name + ":" + is.DOI
+	}
+	return Unknown, ""
+}
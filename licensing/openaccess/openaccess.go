@@ -0,0 +1,193 @@
+// Package openaccess decides, whether a record is open access, based on
+// various sources of evidence: source ids, AMSL free content information,
+// KBART files, the DOAJ journal list, license URLs and DOI lists, like an
+// Unpaywall snapshot. Resolvers are asked in order and the first one that
+// knows decides. The decision is recorded along with the evidence it was
+// based on.
+package openaccess
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/miku/span/filter"
+	"github.com/miku/span/formats/finc"
+)
+
+// Status is the open access status of a record, as far as a resolver knows.
+type Status int
+
+const (
+	// Unknown means, the resolver has no information about the record.
+	Unknown Status = iota
+	// Open means, the record is open access.
+	Open
+	// Closed means, the record is not open access.
+	Closed
+)
+
+// String returns a readable status.
+func (s Status) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case Closed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// Resolver decides on the open access status of a record. The evidence is a
+// short description of what the decision was based on, e.g.
+// "doaj:1234-5678", and is empty, if the status is unknown.
+type Resolver interface {
+	Resolve(is finc.IntermediateSchema) (status Status, evidence string)
+}
+
+// Chain asks a list of resolvers in order, the first one that knows the
+// status decides.
+type Chain []Resolver
+
+// Resolve returns the status of the first resolver, that knows the record.
+func (c Chain) Resolve(is finc.IntermediateSchema) (Status, string) {
+	for _, r := range c {
+		if status, evidence := r.Resolve(is); status != Unknown {
+			return status, evidence
+		}
+	}
+	return Unknown, ""
+}
+
+// Apply sets x.oa and x.oa_evidence of a record. If no resolver knows the
+// record, it is left unchanged.
+func (c Chain) Apply(is *finc.IntermediateSchema) {
+	status, evidence := c.Resolve(*is)
+	if status == Unknown {
+		return
+	}
+	is.OpenAccess = status == Open
+	is.OpenAccessEvidence = evidence
+}
+
+// SourceList assigns a fixed status to all records of a number of sources,
+// e.g. to always or never set x.oa for a source.
+type SourceList struct {
+	Status  Status
+	Sources map[string]bool
+}
+
+// Resolve returns the fixed status for listed sources.
+func (l *SourceList) Resolve(is finc.IntermediateSchema) (Status, string) {
+	if l.Sources[is.SourceID] {
+		return l.Status, "sid:" + is.SourceID
+	}
+	return Unknown, ""
+}
+
+// FreeContent maps a string of the form "Sid:MegaCollection" to a bool,
+// indicating free access (true) and uncertainty or closed access (false).
+type FreeContent map[string]bool
+
+// freeContentItem is a single item from the AMSL API response (2017-12-01).
+type freeContentItem struct {
+	FreeContent    string `json:"freeContent"`
+	MegaCollection string `json:"mega_collection"`
+	Shard          string `json:"shard"`
+	Sid            string `json:"sid"`
+}
+
+// ReadFreeContent reads an AMSL .../list?do=freeContent response. The
+// response is a large array, so items are decoded one at a time and only the
+// resulting map is kept in memory. Decoding the whole array at once used to
+// take up to 40% of 16G.
+func ReadFreeContent(r io.Reader) (FreeContent, error) {
+	// The segmentio decoder lacks Token and More, the standard library
+	// decoder is used to walk the array.
+	var (
+		fc  = make(FreeContent)
+		dec = json.NewDecoder(r)
+	)
+	t, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if t != json.Delim('[') {
+		return nil, fmt.Errorf("openaccess: expected array, got %v", t)
+	}
+	for dec.More() {
+		var item freeContentItem
+		if err := dec.Decode(&item); err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s:%s", item.Sid, item.MegaCollection)
+		switch strings.TrimSpace(strings.ToLower(item.FreeContent)) {
+		case "ja", "yes", "ok", "1", "t", "true":
+			fc[key] = true
+		default: // e.g. "nicht festgelegt"
+			fc[key] = false
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// Resolve returns open, if any collection of the record is free, and closed,
+// if the collections are known, but none is free.
+func (fc FreeContent) Resolve(is finc.IntermediateSchema) (Status, string) {
+	var (
+		status   = Unknown
+		evidence string
+	)
+	for _, c := range is.MegaCollections {
+		key := fmt.Sprintf("%s:%s", is.SourceID, c)
+		v, ok := fc[key]
+		switch {
+		case !ok:
+			continue
+		case v:
+			return Open, "amsl:" + key
+		default:
+			status, evidence = Closed, "amsl:"+key
+		}
+	}
+	return status, evidence
+}
+
+// FilterResolver marks records as open access, that pass a filter, usually a
+// holdings filter with a KBART file of open access titles.
+type FilterResolver struct {
+	Name   string // Used as evidence, e.g. the KBART filename.
+	Filter filter.Filter
+}
+
+// Resolve returns open, if the filter applies.
+func (f *FilterResolver) Resolve(is finc.IntermediateSchema) (Status, string) {
+	if f.Filter.Apply(is) {
+		return Open, "kbart:" + f.Name
+	}
+	return Unknown, ""
+}
+
+// licensePattern matches Creative Commons and public domain licenses, as URL
+// or as name, e.g. "CC BY 4.0".
+var licensePattern = regexp.MustCompile(`(?i)creativecommons\.org/(licenses|publicdomain)/|\bcc[- _]?(by|0|zero)\b|\bpublic domain\b`)
+
+// License marks records with a Creative Commons or public domain license as
+// open access.
+type License struct{}
+
+// Resolve returns open, if any license of the record is an open license.
+func (License) Resolve(is finc.IntermediateSchema) (Status, string) {
+	for _, l := range is.License {
+		if licensePattern.MatchString(l) {
+			return Open, "license:" + l
+		}
+	}
+	return Unknown, ""
+}
@@ -0,0 +1,96 @@
+package openaccess
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/miku/span/formats/finc"
+)
+
+func TestReadFreeContent(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result FreeContent
+		err    bool
+	}{
+		{`[]`, FreeContent{}, false},
+		{`[{"freeContent": "Ja", "mega_collection": "A", "sid": "49"}, {"freeContent": "", "mega_collection": "B", "sid": "49"}]`,
+			FreeContent{"49:A": true, "49:B": false}, false},
+		{`{"freeContent": "ja"}`, nil, true},
+		{`[{"freeContent": "ja"}`, nil, true},
+		{``, nil, true},
+	}
+	for _, c := range cases {
+		fc, err := ReadFreeContent(strings.NewReader(c.s))
+		if (err != nil) != c.err {
+			t.Errorf("ReadFreeContent(%q): got error %v, want error %v", c.s, err, c.err)
+			continue
+		}
+		if !reflect.DeepEqual(fc, c.result) {
+			t.Errorf("ReadFreeContent(%q): got %v, want %v", c.s, fc, c.result)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	fc, err := ReadFreeContent(strings.NewReader(`[
+		{"freeContent": "ja", "mega_collection": "A", "sid": "49"},
+		{"freeContent": "nicht festgelegt", "mega_collection": "B", "sid": "49"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doaj, err := ReadDOAJ(strings.NewReader("Journal title,Journal ISSN (print version),Journal EISSN (online version)\n" +
+		"\"Journal of Examples, Series A\",1050-124X,0028-0844\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dois, err := ReadDOIList(strings.NewReader(`{"doi": "10.1/ABC", "is_oa": true}
+{"doi": "10.1/def", "is_oa": false}
+https://doi.org/10.1/ghi
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dois.Len() != 2 {
+		t.Errorf("got %d DOI, want 2", dois.Len())
+	}
+	chain := Chain{
+		&SourceList{Status: Open, Sources: map[string]bool{"28": true}},
+		&SourceList{Status: Closed, Sources: map[string]bool{"55": true}},
+		fc,
+		doaj,
+		License{},
+		dois,
+	}
+	var tests = []struct {
+		is       finc.IntermediateSchema
+		status   Status
+		evidence string
+	}{
+		{finc.IntermediateSchema{SourceID: "28"}, Open, "sid:28"},
+		{finc.IntermediateSchema{SourceID: "55", ISSN: []string{"1050-124X"}}, Closed, "sid:55"},
+		{finc.IntermediateSchema{SourceID: "49", MegaCollections: []string{"B", "A"}}, Open, "amsl:49:A"},
+		{finc.IntermediateSchema{SourceID: "49", MegaCollections: []string{"B"}, DOI: "10.1/abc"}, Closed, "amsl:49:B"},
+		{finc.IntermediateSchema{EISSN: []string{"0028-0844"}}, Open, "doaj:0028-0844"},
+		{finc.IntermediateSchema{License: []string{"http://creativecommons.org/licenses/by-nc/4.0/"}}, Open,
+			"license:http://creativecommons.org/licenses/by-nc/4.0/"},
+		{finc.IntermediateSchema{License: []string{"CC BY 4.0"}}, Open, "license:CC BY 4.0"},
+		{finc.IntermediateSchema{License: []string{"All rights reserved"}}, Unknown, ""},
+		{finc.IntermediateSchema{DOI: "10.1/abc"}, Open, "doi:10.1/abc"},
+		{finc.IntermediateSchema{DOI: "10.1/GHI"}, Open, "doi:10.1/GHI"},
+		{finc.IntermediateSchema{DOI: "10.1/def"}, Unknown, ""},
+	}
+	for i, test := range tests {
+		status, evidence := chain.Resolve(test.is)
+		if status != test.status || evidence != test.evidence {
+			t.Errorf("[%d] got %s (%s), want %s (%s)", i, status, evidence, test.status, test.evidence)
+		}
+	}
+	is := finc.IntermediateSchema{OpenAccess: true, SourceID: "55"}
+	chain.Apply(&is)
+	if is.OpenAccess || is.OpenAccessEvidence != "sid:55" {
+		t.Errorf("Apply: got %v (%s)", is.OpenAccess, is.OpenAccessEvidence)
+	}
+}
@@ -0,0 +1,42 @@
+package licensing
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// parenthetical matches notes like "(2014-)" or "[Elektronische Ressource]".
+	parenthetical = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+
+	// leadingArticles are removed from the start of a title.
+	leadingArticles = map[string]bool{
+		"a": true, "an": true, "the": true,
+		"der": true, "die": true, "das": true,
+		"el": true, "il": true, "l": true, "la": true, "le": true, "les": true, "lo": true, "los": true, "las": true,
+	}
+)
+
+// NormalizeTitle returns a title in a form suitable for comparison: case
+// folded, without diacritics, parenthetical notes, punctuation and leading
+// articles, e.g. "The Journal of Économie (2014-)" becomes "journal of
+// economie".
+func NormalizeTitle(s string) string {
+	s = parenthetical.ReplaceAllString(s, " ")
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	if v, _, err := transform.String(t, s); err == nil {
+		s = v
+	}
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(fields) > 1 && leadingArticles[fields[0]] {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
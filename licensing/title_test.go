@@ -0,0 +1,22 @@
+package licensing
+
+import "testing"
+
+func TestNormalizeTitle(t *testing.T) {
+	var cases = []struct {
+		s      string
+		result string
+	}{
+		{"Südost-Forschungen (2014-)", "sudost forschungen"},
+		{"The Journal of Économie", "journal of economie"},
+		{"Die Zeit [Elektronische Ressource]", "zeit"},
+		{"Journal of Physics. A, Mathematical and theoretical", "journal of physics a mathematical and theoretical"},
+		{"The", "the"},
+		{"  ", ""},
+	}
+	for _, c := range cases {
+		if result := NormalizeTitle(c.s); result != c.result {
+			t.Errorf("NormalizeTitle(%q): got %q, want %q", c.s, result, c.result)
+		}
+	}
+}
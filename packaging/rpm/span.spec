@@ -34,10 +34,14 @@ install -m 755 span-crossref-sync $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-crossref-table $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-doisniffer $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-export $RPM_BUILD_ROOT/usr/local/bin
+install -m 755 span-filter-diff $RPM_BUILD_ROOT/usr/local/bin
+install -m 755 span-filter-impact $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-freeze $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-hcov $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-import $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-index $RPM_BUILD_ROOT/usr/local/bin
+install -m 755 span-kbart-diff $RPM_BUILD_ROOT/usr/local/bin
+install -m 755 span-kbart-lint $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-local-data $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-mail $RPM_BUILD_ROOT/usr/local/bin
 install -m 755 span-oa-filter $RPM_BUILD_ROOT/usr/local/bin
@@ -74,10 +78,14 @@ rm -rf %{_topdir}/BUILD/%{name}
 /usr/local/bin/span-crossref-table
 /usr/local/bin/span-doisniffer
 /usr/local/bin/span-export
+/usr/local/bin/span-filter-diff
+/usr/local/bin/span-filter-impact
 /usr/local/bin/span-freeze
 /usr/local/bin/span-hcov
 /usr/local/bin/span-import
 /usr/local/bin/span-index
+/usr/local/bin/span-kbart-diff
+/usr/local/bin/span-kbart-lint
 /usr/local/bin/span-local-data
 /usr/local/bin/span-mail
 /usr/local/bin/span-oa-filter
@@ -276,7 +276,9 @@ func NewSkipReader(r *bufio.Reader) *SkipReader {
 	return &SkipReader{r: r}
 }
 
-// ReadString will return only non-empty lines and lines not starting with a comment prefix.
+// ReadString will return only non-empty lines and lines not starting with a
+// comment prefix. Surrounding spaces and line endings are removed, tabs are
+// kept, as they may separate empty columns.
 func (r SkipReader) ReadString(delim byte) (s string, err error) {
 OuterLoop:
 	for {
@@ -284,10 +286,10 @@ OuterLoop:
 		if err == io.EOF {
 			return
 		}
-		s = strings.TrimSpace(s)
-		if s == "" {
+		if strings.TrimSpace(s) == "" {
 			continue
 		}
+		s = strings.Trim(s, " \r\n")
 		for _, p := range r.CommentPrefixes {
 			if strings.HasPrefix(s, p) {
 				continue OuterLoop